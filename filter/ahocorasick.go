@@ -0,0 +1,104 @@
+package filter
+
+// automaton is an Aho-Corasick automaton that finds whether a text contains any of a set of patterns
+// in a single pass over the text, however many patterns there are.
+//
+// It is compiled into a deterministic automaton over byte classes: bytes that occur in no pattern share
+// class 0 and every other byte gets its own class, so each state needs one transition per class
+// instead of one per byte, and matching costs a single table lookup per byte of the text.
+type automaton struct {
+	classes  [256]uint16 // class of every byte
+	stride   int         // number of classes, the width of a row of next
+	next     []int32     // next[state*stride+class] is the row offset of the state reached from state on a byte of class
+	terminal []bool      // terminal[state] is set if a pattern ends at the state or at one of its suffixes
+}
+
+// newAutomaton builds the automaton that matches any of patterns.
+func newAutomaton(patterns []string) *automaton {
+	a := &automaton{stride: 1}
+	for _, pattern := range patterns {
+		for i := 0; i < len(pattern); i++ {
+			if a.classes[pattern[i]] == 0 {
+				a.classes[pattern[i]] = uint16(a.stride)
+				a.stride++
+			}
+		}
+	}
+
+	// build the trie of the patterns, -1 marks a missing transition
+	a.addState()
+	for _, pattern := range patterns {
+		state := 0
+		for i := 0; i < len(pattern); i++ {
+			edge := state*a.stride + int(a.classes[pattern[i]])
+			if a.next[edge] < 0 {
+				a.next[edge] = int32(a.addState())
+			}
+			state = int(a.next[edge])
+		}
+		a.terminal[state] = true
+	}
+
+	// turn the trie into a complete automaton in breadth-first order, so that the failure state of every state,
+	// its longest proper suffix in the trie, is complete before the state itself; a missing transition
+	// then leads where the failure state leads
+	fail := make([]int32, len(a.terminal))
+	queue := make([]int32, 0, len(a.terminal))
+	for c := 0; c < a.stride; c++ {
+		if child := a.next[c]; child < 0 {
+			a.next[c] = 0
+		} else {
+			queue = append(queue, child)
+		}
+	}
+	for len(queue) > 0 {
+		state := int(queue[0])
+		queue = queue[1:]
+		a.terminal[state] = a.terminal[state] || a.terminal[fail[state]]
+		for c := 0; c < a.stride; c++ {
+			edge := state*a.stride + c
+			fallback := a.next[int(fail[state])*a.stride+c]
+			if child := a.next[edge]; child < 0 {
+				a.next[edge] = fallback
+			} else {
+				fail[child] = fallback
+				queue = append(queue, child)
+			}
+		}
+	}
+
+	// store every transition as the offset of the row of its target state, negated if the target is terminal,
+	// so matching needs neither a multiplication nor a second lookup per byte
+	for i, target := range a.next {
+		a.next[i] = target * int32(a.stride)
+		if a.terminal[target] {
+			a.next[i] = ^a.next[i]
+		}
+	}
+	return a
+}
+
+// addState appends a state without transitions and returns its index.
+func (a *automaton) addState() int {
+	for c := 0; c < a.stride; c++ {
+		a.next = append(a.next, -1)
+	}
+	a.terminal = append(a.terminal, false)
+	return len(a.terminal) - 1
+}
+
+// contains reports whether text contains any of the patterns of the automaton.
+func (a *automaton) contains(text string) bool {
+	// the root is terminal if one of the patterns is empty
+	if a.terminal[0] {
+		return true
+	}
+	offset := int32(0)
+	for i := 0; i < len(text); i++ {
+		offset = a.next[offset+int32(a.classes[text[i]])]
+		if offset < 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutomaton_Contains(t *testing.T) {
+	patternSets := [][]string{
+		{"he", "she", "his", "hers"},
+		{"a", "ab", "bab", "bc", "bca", "c", "caa"},
+		{"aaa", "aab", "abb"},
+		{"timeout", "refused", "écrit", "\x00", "ÿ"},
+		{"abc", ""},
+	}
+	texts := []string{"", "h", "ushers", "hi", "this", "sh", "abccab", "bab", "aa", "aabb", "xyz", "connection refused", "time out", "écrire", "a\x00b", "ÿ"}
+	// every string of up to 5 letters of a, b and c
+	words := []string{""}
+	for n := 0; n < 5; n++ {
+		for _, word := range words {
+			if len(word) == n {
+				words = append(words, word+"a", word+"b", word+"c")
+			}
+		}
+	}
+	texts = append(texts, words...)
+
+	for _, patterns := range patternSets {
+		a := newAutomaton(patterns)
+		for _, text := range texts {
+			want := false
+			for _, pattern := range patterns {
+				want = want || strings.Contains(text, pattern)
+			}
+			assert.Equal(t, want, a.contains(text), "patterns %q text %q", patterns, text)
+		}
+	}
+}
+
+func TestFSetFilt_ContainIndex(t *testing.T) {
+	keywords := make([]Filter[string], 0, 20)
+	negated := make([]Filter[string], 0, 20)
+	for i := 0; i < 20; i++ {
+		keyword := fmt.Sprintf("keyword%02d", i)
+		keywords = append(keywords, mustNewFilter(OperatorContain, ValueTypeString, keyword))
+		negated = append(negated, mustNewFilter(OperatorNotContain, ValueTypeString, keyword))
+	}
+	ignoreCase := make([]Filter[string], 0, 16)
+	for i := 0; i < 16; i++ {
+		f, err := mustNewFilter(OperatorContain, ValueTypeString, fmt.Sprintf("CAFÉ%d", i)).WithMatchMode(MatchIgnoreCase | MatchIgnoreAccents)
+		assert.NoError(t, err)
+		ignoreCase = append(ignoreCase, f)
+	}
+	equal := mustNewFilter(OperatorEqual, ValueTypeString, "exact")
+	exists, err := NewPresenceFilter[string](OperatorExists, ValueTypeString)
+	assert.NoError(t, err)
+	missing, err := NewPresenceFilter[string](OperatorNotExists, ValueTypeString)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		filters   []Filter[string]
+		condition Condition
+		data      string
+		ok        bool
+		want      bool
+	}{
+		{name: "OR of contains with a match", filters: keywords, condition: ConditionOr, data: "log line with keyword17 in it", ok: true, want: true},
+		{name: "OR of contains without a match", filters: keywords, condition: ConditionOr, data: "log line with keyword in it", ok: true, want: false},
+		{name: "OR of contains on a missing value", filters: keywords, condition: ConditionOr, ok: false, want: false},
+		{name: "OR with another filter", filters: append([]Filter[string]{equal}, keywords...), condition: ConditionOr, data: "exact", ok: true, want: true},
+		{name: "OR with NotExists on a missing value", filters: append([]Filter[string]{missing}, keywords...), condition: ConditionOr, ok: false, want: true},
+		{name: "OR of contains with match modes", filters: append(keywords, ignoreCase...), condition: ConditionOr, data: "Un cafe3 noir", ok: true, want: true},
+		{name: "OR of contains with match modes without a match", filters: append(keywords, ignoreCase...), condition: ConditionOr, data: "Un café noir", ok: true, want: false},
+		{name: "AND of not contains without a match", filters: negated, condition: ConditionAnd, data: "clean line", ok: true, want: true},
+		{name: "AND of not contains with a match", filters: negated, condition: ConditionAnd, data: "dirty keyword03", ok: true, want: false},
+		{name: "AND of not contains on a missing value", filters: negated, condition: ConditionAnd, ok: false, want: false},
+		{name: "AND with another filter", filters: append([]Filter[string]{exists}, negated...), condition: ConditionAnd, data: "clean line", ok: true, want: true},
+		{name: "AND of contains", filters: keywords, condition: ConditionAnd, data: "keyword01", ok: true, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getter := func() (string, bool) { return tt.data, tt.ok }
+			fset := NewFilterSet(getter, tt.filters, tt.condition)
+			assert.Equal(t, tt.want, fset.filt())
+			// a set without the index checks every filter on its own
+			plain := FSet[string]{DataGetter: getter, Filters: tt.filters, Condition: tt.condition}
+			assert.Equal(t, plain.filt(), fset.filt())
+		})
+	}
+}
+
+func BenchmarkFSetFilt_Contain(b *testing.B) {
+	for _, n := range []int{8, 16, 32, 64, 2000} {
+		filters := make([]Filter[string], n)
+		for i := range filters {
+			filters[i] = mustNewFilter(OperatorContain, ValueTypeString, fmt.Sprintf("blocked-keyword-%d", i))
+		}
+		line := strings.Repeat("GET /api/v1/items?page=2 200 OK ", 4)
+		getter := func() (string, bool) { return line, true }
+
+		b.Run(fmt.Sprintf("%d/index", n), func(b *testing.B) {
+			fset := NewFilterSet(getter, filters, ConditionOr)
+			for i := 0; i < b.N; i++ {
+				fset.filt()
+			}
+		})
+		b.Run(fmt.Sprintf("%d/plain", n), func(b *testing.B) {
+			fset := FSet[string]{DataGetter: getter, Filters: filters, Condition: ConditionOr}
+			for i := 0; i < b.N; i++ {
+				fset.filt()
+			}
+		})
+	}
+}
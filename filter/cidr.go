@@ -0,0 +1,47 @@
+package filter
+
+import "net/netip"
+
+// NewCIDRFilter creates an InCIDR or NotInCIDR filter matching an IP address that is (not) in any of prefixes,
+// e.g. 10.0.0.0/8 and 192.168.0.0/16. Host bits of the prefixes are ignored.
+// An InCIDR filter created with NewFilter matches the single address of its value.
+// It returns ErrInvalidPrefix if a prefix is not valid.
+func NewCIDRFilter(operator Operator, valueType ValueType, prefixes []netip.Prefix) (Filter[netip.Addr], error) {
+	if !isCIDROperator(operator) {
+		return Filter[netip.Addr]{}, ErrInvalidOperator
+	}
+	f := Filter[netip.Addr]{
+		operator:  operator,
+		valueType: valueType,
+		prefixes:  append([]netip.Prefix{}, prefixes...),
+	}
+
+	err := f.Validate()
+	if err != nil {
+		return Filter[netip.Addr]{}, err
+	}
+	f.prepare()
+	return f, nil
+}
+
+// isCIDROperator reports whether operator checks whether an IP address is in a list of prefixes.
+func isCIDROperator(operator Operator) bool {
+	return operator == OperatorInCIDR || operator == OperatorNotInCIDR
+}
+
+// hostPrefix returns the prefix holding only addr, or an invalid prefix if addr is not a valid address.
+func hostPrefix(addr netip.Addr) netip.Prefix {
+	return netip.PrefixFrom(addr, addr.BitLen())
+}
+
+// filtCIDR checks if the data is an IP address in one of the prefixes of the filter.
+// IPv4-mapped IPv6 addresses such as ::ffff:10.0.0.1 count as their IPv4 address.
+func (f Filter[T]) filtCIDR(data T) bool {
+	addr := any(data).(netip.Addr).Unmap()
+	for _, prefix := range f.prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package filter
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilter_CIDR(t *testing.T) {
+	private := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("192.168.1.7/24"), netip.MustParsePrefix("fd00::/8")}
+	in, err := NewCIDRFilter(OperatorInCIDR, ValueTypeIP, private)
+	assert.NoError(t, err)
+	notIn, err := NewCIDRFilter(OperatorNotInCIDR, ValueTypeIP, private)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{addr: "10.1.1.1", want: true},
+		{addr: "110.1.1.1", want: false},
+		{addr: "192.168.1.200", want: true},
+		{addr: "192.168.2.1", want: false},
+		{addr: "::ffff:10.0.0.1", want: true},
+		{addr: "fd12::1", want: true},
+		{addr: "2001:db8::1", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			addr := netip.MustParseAddr(tt.addr)
+			assert.Equal(t, tt.want, in.filtData(addr))
+			assert.Equal(t, !tt.want, notIn.filtData(addr))
+		})
+	}
+
+	// host bits are dropped
+	assert.Equal(t, netip.MustParsePrefix("192.168.1.0/24"), in.Prefixes()[1])
+
+	host := mustNewFilter(OperatorInCIDR, ValueTypeIP, netip.MustParseAddr("10.0.0.1"))
+	assert.True(t, host.filtData(netip.MustParseAddr("10.0.0.1")))
+	assert.False(t, host.filtData(netip.MustParseAddr("10.0.0.2")))
+
+	equal := mustNewFilter(OperatorEqual, ValueTypeIP, netip.MustParseAddr("10.0.0.1"))
+	assert.True(t, equal.filtData(netip.MustParseAddr("::ffff:10.0.0.1")))
+	assert.False(t, equal.filtData(netip.MustParseAddr("10.0.0.11")))
+}
+
+func TestFilter_CIDR_Validate(t *testing.T) {
+	_, err := NewCIDRFilter(OperatorIn, ValueTypeIP, nil)
+	assert.ErrorIs(t, err, ErrInvalidOperator)
+
+	_, err = NewCIDRFilter(OperatorInCIDR, ValueTypeString, []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")})
+	assert.ErrorIs(t, err, ErrInvalidValueType)
+
+	_, err = NewCIDRFilter(OperatorInCIDR, ValueTypeIP, []netip.Prefix{{}})
+	assert.ErrorIs(t, err, ErrInvalidPrefix)
+
+	_, err = NewFilter(OperatorInCIDR, ValueTypeIP, netip.Addr{})
+	assert.ErrorIs(t, err, ErrInvalidPrefix)
+
+	_, err = NewFilter(OperatorLessThan, ValueTypeIP, netip.MustParseAddr("10.0.0.1"))
+	assert.ErrorIs(t, err, ErrInvalidOperator)
+}
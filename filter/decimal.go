@@ -0,0 +1,144 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Decimal is an exact decimal number of any precision, such as an amount of money.
+// Unlike float64, 0.1 + 0.2 is 0.3 and 1234.50 is exactly 123450 hundredths.
+//
+// A Decimal is kept in normal form, so decimals of equal value are equal with ==, e.g. 1234.50 and 1234.5,
+// which lets them be compared and used in In sets without any tolerance.
+// The zero Decimal is 0.
+type Decimal struct {
+	neg  bool
+	coef string // decimal digits without leading or trailing zeros, empty for 0
+	exp  int    // the value is coef × 10^exp
+}
+
+// maxDecimalExponent bounds the exponent of a parsed Decimal, so that printing it cannot take unbounded memory.
+const maxDecimalExponent = 1 << 15
+
+// NewDecimal returns the decimal unscaled × 10^-scale, e.g. NewDecimal(123450, 2) is 1234.50.
+func NewDecimal(unscaled int64, scale int) Decimal {
+	digits := strconv.FormatInt(unscaled, 10)
+	return normalizeDecimal(strings.HasPrefix(digits, "-"), strings.TrimPrefix(digits, "-"), -scale)
+}
+
+// ParseDecimal parses a decimal number such as "1234.50", "-0.07", "+12" or "1.5e3" without going through float64.
+func ParseDecimal(s string) (Decimal, error) {
+	text := s
+	neg := false
+	if text != "" && (text[0] == '-' || text[0] == '+') {
+		neg = text[0] == '-'
+		text = text[1:]
+	}
+
+	exp := 0
+	if i := strings.IndexAny(text, "eE"); i >= 0 {
+		n, err := strconv.Atoi(text[i+1:])
+		if err != nil || n < -maxDecimalExponent || n > maxDecimalExponent {
+			return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+		}
+		exp = n
+		text = text[:i]
+	}
+
+	whole, fraction, _ := strings.Cut(text, ".")
+	if whole+fraction == "" || !isDigits(whole) || !isDigits(fraction) {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	return normalizeDecimal(neg, whole+fraction, exp-len(fraction)), nil
+}
+
+// isDigits reports whether s consists of ASCII digits only.
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeDecimal returns the Decimal of the digits × 10^exp in normal form.
+func normalizeDecimal(neg bool, digits string, exp int) Decimal {
+	digits = strings.TrimLeft(digits, "0")
+	trimmed := strings.TrimRight(digits, "0")
+	if trimmed == "" {
+		return Decimal{}
+	}
+	return Decimal{neg: neg, coef: trimmed, exp: exp + len(digits) - len(trimmed)}
+}
+
+// String returns the decimal in plain notation without trailing zeros, e.g. "1234.5", "-0.07" or "1500".
+func (d Decimal) String() string {
+	if d.coef == "" {
+		return "0"
+	}
+
+	var b strings.Builder
+	if d.neg {
+		b.WriteByte('-')
+	}
+	switch point := len(d.coef) + d.exp; {
+	case d.exp >= 0:
+		b.WriteString(d.coef)
+		b.WriteString(strings.Repeat("0", d.exp))
+	case point > 0:
+		b.WriteString(d.coef[:point])
+		b.WriteByte('.')
+		b.WriteString(d.coef[point:])
+	default:
+		b.WriteString("0.")
+		b.WriteString(strings.Repeat("0", -point))
+		b.WriteString(d.coef)
+	}
+	return b.String()
+}
+
+// Compare returns -1, 0 or 1 as d is less than, equal to or greater than other.
+func (d Decimal) Compare(other Decimal) int {
+	switch {
+	case d.sign() != other.sign():
+		if d.sign() < other.sign() {
+			return -1
+		}
+		return 1
+	case d.coef == "":
+		return 0
+	}
+
+	c := compareMagnitude(d, other)
+	if d.neg {
+		return -c
+	}
+	return c
+}
+
+// sign returns -1, 0 or 1 as d is negative, zero or positive.
+func (d Decimal) sign() int {
+	switch {
+	case d.coef == "":
+		return 0
+	case d.neg:
+		return -1
+	}
+	return 1
+}
+
+// compareMagnitude compares the absolute values of two non-zero decimals.
+// The one whose leading digit has the higher place value is greater. With the same place value,
+// comparing the digits as text decides, as neither of them has trailing zeros.
+func compareMagnitude(a, b Decimal) int {
+	placeA, placeB := len(a.coef)+a.exp, len(b.coef)+b.exp
+	if placeA != placeB {
+		if placeA < placeB {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a.coef, b.coef)
+}
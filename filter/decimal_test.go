@@ -0,0 +1,112 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func TestParseDecimal(t *testing.T) {
+	tests := map[string]string{
+		"1234.50":                        "1234.5",
+		"-0.07":                          "-0.07",
+		"+12":                            "12",
+		"0012.300":                       "12.3",
+		"1.5e3":                          "1500",
+		"15E-4":                          "0.0015",
+		".5":                             "0.5",
+		"5.":                             "5",
+		"-0.000":                         "0",
+		"1e-20":                          "0.00000000000000000001",
+		"12345678901234567890.123456789": "12345678901234567890.123456789",
+	}
+	for text, want := range tests {
+		t.Run(text, func(t *testing.T) {
+			got, err := ParseDecimal(text)
+			assert.NoError(t, err)
+			assert.Equal(t, want, got.String())
+		})
+	}
+
+	for _, text := range []string{"", "-", ".", "1.2.3", "1,5", "1e", "1e99999", "0x10", "NaN", "Inf", " 1", "1_000"} {
+		_, err := ParseDecimal(text)
+		assert.Error(t, err, text)
+	}
+}
+
+func TestDecimal_NormalForm(t *testing.T) {
+	assert.Equal(t, mustParseDecimal("1234.5"), mustParseDecimal("1234.50"))
+	assert.Equal(t, mustParseDecimal("1500"), mustParseDecimal("1.5e3"))
+	assert.Equal(t, Decimal{}, mustParseDecimal("-0.00"))
+	assert.Equal(t, mustParseDecimal("1234.50"), NewDecimal(123450, 2))
+	assert.Equal(t, mustParseDecimal("-0.07"), NewDecimal(-7, 2))
+	assert.Equal(t, mustParseDecimal("1200"), NewDecimal(12, -2))
+	assert.Equal(t, "0", Decimal{}.String())
+}
+
+func TestDecimal_Compare(t *testing.T) {
+	ordered := []string{"-1000", "-99.99", "-0.0700001", "-0.07", "0", "0.0000001", "0.3", "0.30000000000000004", "1", "9.99", "10", "1234.5"}
+	for i, a := range ordered {
+		for j, b := range ordered {
+			want := 0
+			if i < j {
+				want = -1
+			} else if i > j {
+				want = 1
+			}
+			assert.Equal(t, want, mustParseDecimal(a).Compare(mustParseDecimal(b)), "%s vs %s", a, b)
+		}
+	}
+}
+
+func TestFilter_Decimal(t *testing.T) {
+	tests := []struct {
+		name     string
+		operator Operator
+		value    string
+		data     string
+		want     bool
+	}{
+		{name: "Equal ignores trailing zeros", operator: OperatorEqual, value: "1234.50", data: "1234.5", want: true},
+		{name: "Equal is exact", operator: OperatorEqual, value: "0.3", data: "0.30000000000000004", want: false},
+		{name: "Not equal is exact", operator: OperatorNotEqual, value: "1234.5", data: "1234.5000000001", want: true},
+		{name: "Less than", operator: OperatorLessThan, value: "100", data: "99.99", want: true},
+		{name: "Less than or equal", operator: OperatorLessThanOrEqual, value: "-0.07", data: "-0.070", want: true},
+		{name: "Greater than", operator: OperatorGreaterThan, value: "-1", data: "-0.5", want: true},
+		{name: "Greater than or equal", operator: OperatorGreaterThanOrEqual, value: "0.01", data: "0.009", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := mustNewFilter(tt.operator, ValueTypeDecimal, mustParseDecimal(tt.value))
+			assert.Equal(t, tt.want, f.filtData(mustParseDecimal(tt.data)))
+		})
+	}
+
+	in, err := NewSetFilter(OperatorIn, ValueTypeDecimal, []Decimal{mustParseDecimal("1234.50"), mustParseDecimal("99.99")})
+	assert.NoError(t, err)
+	assert.True(t, in.filtData(mustParseDecimal("1234.5")))
+	assert.False(t, in.filtData(mustParseDecimal("99.9")))
+
+	between, err := NewRangeFilter(ValueTypeDecimal, mustParseDecimal("0.10"), mustParseDecimal("0.30"), BoundsInclusive)
+	assert.NoError(t, err)
+	assert.True(t, between.filtData(mustParseDecimal("0.3")))
+	assert.False(t, between.filtData(mustParseDecimal("0.30000000000000004")))
+
+	_, err = NewRangeFilter(ValueTypeDecimal, mustParseDecimal("1"), mustParseDecimal("0.99"), BoundsInclusive)
+	assert.ErrorIs(t, err, ErrInvalidRange)
+	_, err = NewFilter(OperatorContain, ValueTypeDecimal, mustParseDecimal("1"))
+	assert.ErrorIs(t, err, ErrInvalidOperator)
+	_, err = NewFilter(OperatorEqual, ValueTypeNumber, mustParseDecimal("1"))
+	assert.NoError(t, err)
+	_, err = NewFilter(OperatorEqual, ValueTypeString, mustParseDecimal("1"))
+	assert.ErrorIs(t, err, ErrInvalidValueType)
+}
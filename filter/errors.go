@@ -1,3 +1,13 @@
 package filter
 
-// TODO: 하드코딩된 에러들 옮기기
+import "errors"
+
+var (
+	ErrInvalidValueType = errors.New("invalid value type")
+	ErrInvalidOperator  = errors.New("invalid operator")
+	ErrInvalidPattern   = errors.New("invalid pattern")
+	ErrInvalidMatchMode = errors.New("invalid match mode")
+	ErrInvalidRange     = errors.New("invalid range")
+	ErrInvalidDistance  = errors.New("invalid distance")
+	ErrInvalidPrefix    = errors.New("invalid prefix")
+)
@@ -1,8 +1,12 @@
 package filter
 
 import (
-	"errors"
+	"cmp"
+	"fmt"
 	"math"
+	"net/netip"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
 )
@@ -13,16 +17,30 @@ import (
 // Supported operators per ValueType:
 // - String ValueType:
 //   - Contains
+//   - NotContain
+//   - StartsWith
+//   - EndsWith
 //   - Equal
 //   - NotEqual
+//   - Match (regular expression, compiled once by NewFilter)
+//   - NotMatch
+//   - Glob (wildcards *, ? and [a-z], compiled once by NewFilter)
+//   - Like (SQL LIKE wildcards % and _, compiled once by NewFilter)
+//   - Fuzzy (within an edit distance of the value, see NewFuzzyFilter)
+//   - In (membership in a set of values, built once by NewSetFilter)
+//   - NotIn
+//   - IsEmpty (the value is present and empty)
 //
-// - Number ValueType:
+// - Number ValueType (int, int64, uint64 and Decimal compared exactly, float64 and float32 with a small tolerance):
 //   - Equal
 //   - NotEqual
 //   - LessThan
 //   - LessThanOrEqual
 //   - MoreThan
 //   - MoreThanOrEqual
+//   - In
+//   - NotIn
+//   - Between (range from the value to an upper end, built by NewRangeFilter)
 //
 // - Datetime ValueType:
 //   - Equal
@@ -31,12 +49,71 @@ import (
 //   - LessThanOrEqual
 //   - MoreThan
 //   - MoreThanOrEqual
+//   - Between
+//
+// - Duration ValueType (compared exactly, unlike floats):
+//   - Equal
+//   - NotEqual
+//   - LessThan
+//   - LessThanOrEqual
+//   - MoreThan
+//   - MoreThanOrEqual
+//   - In
+//   - NotIn
+//   - Between
+//
+// - IP ValueType (netip.Addr):
+//   - Equal
+//   - NotEqual
+//   - InCIDR (in any of a list of prefixes, built by NewCIDRFilter)
+//   - NotInCIDR
+//
+// - Decimal ValueType (compared exactly, see Decimal):
+//   - Equal
+//   - NotEqual
+//   - LessThan
+//   - LessThanOrEqual
+//   - MoreThan
+//   - MoreThanOrEqual
+//   - In
+//   - NotIn
+//   - Between
+//
+// - Semver ValueType (compared by precedence, see Semver.Compare):
+//   - Equal
+//   - NotEqual
+//   - LessThan
+//   - LessThanOrEqual
+//   - MoreThan
+//   - MoreThanOrEqual
+//   - Between
+//   - Caret (^1.2.3, from the value up to the next major version)
+//   - Tilde (~1.2.3, from the value up to the next minor version)
+//
+// - Bool ValueType:
+//   - Equal
+//   - NotEqual
+//
+// Every ValueType also supports Exists and NotExists, which test whether the DataGetter of the FSet found
+// the value rather than comparing it, see NewPresenceFilter.
+//
+// String filters compare text byte by byte unless another MatchMode is set with WithMatchMode.
 //
 // T represents the type of the Value and must match the specified ValueType.
 type Filter[T Value] struct {
-	operator  Operator
-	valueType ValueType
-	value     T
+	operator   Operator
+	valueType  ValueType
+	value      T
+	mode       MatchMode
+	normalized string         // value normalized for mode
+	pattern    *regexp.Regexp // compiled value of a Match/NotMatch/Glob/Like filter
+	values     []T            // members of an In/NotIn filter
+	set        map[T]struct{} // values of an In/NotIn filter, normalized for mode
+	upper      T              // upper end of a Between filter, value is the lower end, or of a Caret/Tilde range
+	bounds     Bounds         // ends excluded from a Between filter, or the upper end of a Caret/Tilde range
+	distance   int            // maximum edit distance of a Fuzzy filter
+	fuzzyMode  FuzzyMode      // how a Fuzzy filter measures the distance
+	prefixes   []netip.Prefix // prefixes of an InCIDR/NotInCIDR filter
 }
 
 func NewFilter[T Value](operator Operator, valueType ValueType, value T) (Filter[T], error) {
@@ -45,23 +122,305 @@ func NewFilter[T Value](operator Operator, valueType ValueType, value T) (Filter
 		valueType: valueType,
 		value:     value,
 	}
+	// a single value is a set of one for In/NotIn and a range of one for Between
+	if isSetOperator(operator) {
+		f.values = []T{value}
+	}
+	if operator == OperatorBetween {
+		f.upper = value
+	}
+	if operator == OperatorFuzzy {
+		f.distance = FuzzyAuto
+	}
+	if addr, ok := any(value).(netip.Addr); ok && isCIDROperator(operator) {
+		f.prefixes = []netip.Prefix{hostPrefix(addr)}
+	}
 
 	err := f.Validate()
 	if err != nil {
 		return Filter[T]{}, err
 	}
+	f.prepare()
 	return f, nil
 }
 
+// NewSetFilter creates an In or NotIn filter matching data that is (not) one of values.
+// The values are put into a hash set once, so a membership check costs the same for any number of values.
+// Numbers are looked up exactly, without the tolerance Equal allows for floats.
+func NewSetFilter[T Value](operator Operator, valueType ValueType, values []T) (Filter[T], error) {
+	if !isSetOperator(operator) {
+		return Filter[T]{}, ErrInvalidOperator
+	}
+	f := Filter[T]{
+		operator:  operator,
+		valueType: valueType,
+		values:    append([]T{}, values...),
+	}
+
+	err := f.Validate()
+	if err != nil {
+		return Filter[T]{}, err
+	}
+	f.prepare()
+	return f, nil
+}
+
+// NewRangeFilter creates a Between filter matching data from lower to upper.
+// bounds selects whether each end belongs to the range, e.g. BoundsExcludeUpper for lower <= data < upper.
+// It returns ErrInvalidRange if lower is greater than upper.
+func NewRangeFilter[T Value](valueType ValueType, lower, upper T, bounds Bounds) (Filter[T], error) {
+	f := Filter[T]{
+		operator:  OperatorBetween,
+		valueType: valueType,
+		value:     lower,
+		upper:     upper,
+		bounds:    bounds,
+	}
+
+	err := f.Validate()
+	if err != nil {
+		return Filter[T]{}, err
+	}
+	f.prepare()
+	return f, nil
+}
+
+// NewFuzzyFilter creates a Fuzzy filter matching data that is at most distance edits away from value,
+// where distance may be FuzzyAuto to pick it from the length of value.
+// A Fuzzy filter created with NewFilter uses FuzzyAuto and FuzzyLevenshtein.
+// It returns ErrInvalidDistance if distance is negative and not FuzzyAuto.
+func NewFuzzyFilter[T Value](valueType ValueType, value T, distance int, mode FuzzyMode) (Filter[T], error) {
+	f := Filter[T]{
+		operator:  OperatorFuzzy,
+		valueType: valueType,
+		value:     value,
+		distance:  distance,
+		fuzzyMode: mode,
+	}
+
+	err := f.Validate()
+	if err != nil {
+		return Filter[T]{}, err
+	}
+	f.prepare()
+	return f, nil
+}
+
+// NewPresenceFilter creates an Exists, NotExists or IsEmpty filter, which takes no value.
+// Exists and NotExists match on the ok flag of the FSet's DataGetter, e.g. on whether a JSON key or CSV column
+// is there, and IsEmpty matches a string that is present but empty.
+func NewPresenceFilter[T Value](operator Operator, valueType ValueType) (Filter[T], error) {
+	if !isPresenceOperator(operator) {
+		return Filter[T]{}, ErrInvalidOperator
+	}
+	var zero T
+	return NewFilter(operator, valueType, zero)
+}
+
+// isPresenceOperator reports whether operator looks at the presence of the value instead of comparing it.
+func isPresenceOperator(operator Operator) bool {
+	return operator == OperatorExists || operator == OperatorNotExists || operator == OperatorIsEmpty
+}
+
+// WithMatchMode returns a copy of a string filter that compares text according to mode.
+// The mode applies to every string operator, e.g. Contain with MatchIgnoreCase matches "ERROR" in "an error".
+func (f Filter[T]) WithMatchMode(mode MatchMode) (Filter[T], error) {
+	f.mode = mode
+	if err := f.Validate(); err != nil {
+		return Filter[T]{}, err
+	}
+	f.prepare()
+	return f, nil
+}
+
+// prepare precomputes what evaluating a valid filter needs from its value, so it is done once per filter.
+func (f *Filter[T]) prepare() {
+	if usesPattern(f.operator) {
+		f.pattern, _ = f.compile()
+	}
+	if f.mode != MatchCaseSensitive {
+		f.normalized = normalizeString(any(f.value).(string), f.mode)
+	}
+	for i, prefix := range f.prefixes {
+		f.prefixes[i] = prefix.Masked()
+	}
+	if v, ok := any(f.value).(Semver); ok && (f.operator == OperatorCaret || f.operator == OperatorTilde) {
+		f.upper = any(compatibleUpper(f.operator, v)).(T)
+		f.bounds = BoundsExcludeUpper
+	}
+	if isSetOperator(f.operator) {
+		f.set = make(map[T]struct{}, len(f.values))
+		for _, value := range f.values {
+			f.set[f.normalize(value)] = struct{}{}
+		}
+	}
+}
+
+// normalize returns a string value normalized for the filter's mode and any other value unchanged.
+func (f Filter[T]) normalize(value T) T {
+	if f.mode == MatchCaseSensitive {
+		return value
+	}
+	return any(normalizeString(any(value).(string), f.mode)).(T)
+}
+
+// usesPattern reports whether operator matches data against a regular expression built from the value.
+func usesPattern(operator Operator) bool {
+	switch operator {
+	case OperatorMatch, OperatorNotMatch, OperatorGlob, OperatorLike:
+		return true
+	}
+	return false
+}
+
+// isSetOperator reports whether operator checks the membership in a set of values.
+func isSetOperator(operator Operator) bool {
+	return operator == OperatorIn || operator == OperatorNotIn
+}
+
+func (f Filter[T]) Operator() Operator {
+	return f.operator
+}
+
+func (f Filter[T]) ValueType() ValueType {
+	return f.valueType
+}
+
+func (f Filter[T]) Value() T {
+	return f.value
+}
+
+func (f Filter[T]) MatchMode() MatchMode {
+	return f.mode
+}
+
+// Values returns the members of an In/NotIn filter.
+func (f Filter[T]) Values() []T {
+	return f.values
+}
+
+// Upper returns the upper end of a Between filter, whose lower end is Value.
+func (f Filter[T]) Upper() T {
+	return f.upper
+}
+
+func (f Filter[T]) Bounds() Bounds {
+	return f.bounds
+}
+
+// Distance returns the maximum edit distance of a Fuzzy filter, which may be FuzzyAuto.
+func (f Filter[T]) Distance() int {
+	return f.distance
+}
+
+func (f Filter[T]) FuzzyMode() FuzzyMode {
+	return f.fuzzyMode
+}
+
+// Prefixes returns the prefixes of an InCIDR/NotInCIDR filter.
+func (f Filter[T]) Prefixes() []netip.Prefix {
+	return f.prefixes
+}
+
+// Info describes the filter independently of its value type.
+func (f Filter[T]) Info() FilterInfo {
+	info := FilterInfo{Operator: f.operator, ValueType: f.valueType, Value: f.value, MatchMode: f.mode}
+	if isSetOperator(f.operator) {
+		info.Value = nil
+		info.Values = make([]any, len(f.values))
+		for i, value := range f.values {
+			info.Values[i] = value
+		}
+	}
+	if isCIDROperator(f.operator) {
+		info.Value = nil
+		info.Values = make([]any, len(f.prefixes))
+		for i, prefix := range f.prefixes {
+			info.Values[i] = prefix
+		}
+	}
+	if f.operator == OperatorBetween {
+		info.Upper = f.upper
+		info.Bounds = f.bounds
+	}
+	if f.operator == OperatorFuzzy {
+		info.Distance = f.distance
+		info.FuzzyMode = f.fuzzyMode
+	}
+	return info
+}
+
+// equal reports whether two filters always give the same result.
+func (f Filter[T]) equal(other Filter[T]) bool {
+	return f.operator == other.operator && f.valueType == other.valueType && any(f.value) == any(other.value) &&
+		f.mode == other.mode && slices.Equal(f.values, other.values) && any(f.upper) == any(other.upper) &&
+		f.bounds == other.bounds && f.distance == other.distance && f.fuzzyMode == other.fuzzyMode &&
+		slices.Equal(f.prefixes, other.prefixes)
+}
+
+// filterKey holds the comparable fields of a filter, so that equal filters can be found with a map.
+// Filters with the same key are equal unless their lists of values or prefixes differ.
+type filterKey[T Value] struct {
+	operator  Operator
+	valueType ValueType
+	value     T
+	mode      MatchMode
+	upper     T
+	bounds    Bounds
+	distance  int
+	fuzzyMode FuzzyMode
+}
+
+func (f Filter[T]) key() filterKey[T] {
+	return filterKey[T]{
+		operator:  f.operator,
+		valueType: f.valueType,
+		value:     f.value,
+		mode:      f.mode,
+		upper:     f.upper,
+		bounds:    f.bounds,
+		distance:  f.distance,
+		fuzzyMode: f.fuzzyMode,
+	}
+}
+
 // Validate checks the validity of the Filter.
-// It verifies that the actual Value of the Filter matches the specified ValueType
-// and ensures that the assigned Operator is valid for the given ValueType.
+// It verifies that the actual Value of the Filter matches the specified ValueType,
+// ensures that the assigned Operator is valid for the given ValueType,
+// that a MatchMode is only set on a string filter,
+// that the Value of a Match/NotMatch/Glob/Like filter is a valid pattern
+// that the lower end of a Between filter is not greater than its upper end
+// that the distance of a Fuzzy filter is not negative
+// and that the prefixes of an InCIDR/NotInCIDR filter are valid.
 func (f Filter[T]) Validate() error {
 	if !validateValueType(f.valueType, f.value) {
-		return errors.New("invalid value type")
+		return ErrInvalidValueType
 	}
 	if !validateOperator(f.operator, f.valueType) {
-		return errors.New("invalid operator")
+		return ErrInvalidOperator
+	}
+	if f.mode != MatchCaseSensitive && (f.valueType != ValueTypeString || f.mode&^matchModeAll != 0) {
+		return ErrInvalidMatchMode
+	}
+	if usesPattern(f.operator) {
+		if _, err := f.compile(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidPattern, err)
+		}
+	}
+	if f.operator == OperatorBetween {
+		if f.bounds&^BoundsExclusive != 0 || !compareComparable(f.upper, f.value, OperatorLessThanOrEqual) {
+			return ErrInvalidRange
+		}
+	}
+	if f.operator == OperatorFuzzy {
+		if (f.distance < 0 && f.distance != FuzzyAuto) || f.fuzzyMode&^fuzzyModeAll != 0 {
+			return ErrInvalidDistance
+		}
+	}
+	for _, prefix := range f.prefixes {
+		if !prefix.IsValid() {
+			return ErrInvalidPrefix
+		}
 	}
 	return nil
 }
@@ -69,7 +428,7 @@ func (f Filter[T]) Validate() error {
 // validateValueType checks if the actual type of the Value matches the specified ValueType.
 func validateValueType[T Value](valueType ValueType, value T) bool {
 	switch any(value).(type) {
-	case int, float64, float32:
+	case int, int64, uint64, float64, float32:
 		if valueType != ValueTypeNumber {
 			return false
 		}
@@ -81,29 +440,92 @@ func validateValueType[T Value](valueType ValueType, value T) bool {
 		if valueType != ValueTypeDatetime {
 			return false
 		}
+	case bool:
+		if valueType != ValueTypeBool {
+			return false
+		}
+	case time.Duration:
+		if valueType != ValueTypeDuration {
+			return false
+		}
+	case netip.Addr:
+		if valueType != ValueTypeIP {
+			return false
+		}
+	case Semver:
+		if valueType != ValueTypeSemver {
+			return false
+		}
+	case Decimal:
+		// a Decimal number holds integers and fractions alike
+		if valueType != ValueTypeDecimal && valueType != ValueTypeNumber {
+			return false
+		}
 	}
 	return true
 }
 
 // validateOperator checks if the specified Operator is valid for the given ValueType.
 // It ensures that:
-// - ValueTypeNumber and ValueTypeDatetime do not use the OperatorContain.
-// - ValueTypeString does not use operators such as LessThan, LessThanOrEqual, MoreThan, or MoreThanOrEqual.
+// - ValueTypeNumber only uses Equal, NotEqual, LessThan, LessThanOrEqual, MoreThan, MoreThanOrEqual, In, NotIn and Between.
+// - ValueTypeDatetime only uses Equal, NotEqual, LessThan, LessThanOrEqual, MoreThan, MoreThanOrEqual and Between.
+// - ValueTypeDuration and ValueTypeDecimal use the same operators as ValueTypeNumber.
+// - ValueTypeString only uses Contain, NotContain, StartsWith, EndsWith, Equal, NotEqual, Match, NotMatch, Glob, Like, Fuzzy, In, NotIn and IsEmpty.
+// - ValueTypeIP only uses Equal, NotEqual, InCIDR and NotInCIDR.
+// - ValueTypeSemver only uses Equal, NotEqual, LessThan, LessThanOrEqual, MoreThan, MoreThanOrEqual, Between, Caret and Tilde.
+// - ValueTypeBool only uses Equal and NotEqual.
+// - Every ValueType uses Exists and NotExists.
 func validateOperator(operator Operator, valueType ValueType) bool {
 	switch valueType {
-	case ValueTypeNumber, ValueTypeDatetime:
-		if operator == OperatorContain {
-			return false
+	case ValueTypeNumber, ValueTypeDatetime, ValueTypeDuration, ValueTypeDecimal:
+		switch operator {
+		case OperatorEqual, OperatorNotEqual, OperatorLessThan, OperatorLessThanOrEqual,
+			OperatorGreaterThan, OperatorGreaterThanOrEqual, OperatorBetween, OperatorExists, OperatorNotExists:
+			return true
+		case OperatorIn, OperatorNotIn:
+			// time.Time values that are Equal may differ in location, so they cannot be hashed
+			return valueType != ValueTypeDatetime
 		}
 	case ValueTypeString:
-		if operator == OperatorLessThan || operator == OperatorLessThanOrEqual {
-			return false
+		switch operator {
+		case OperatorContain, OperatorNotContain, OperatorStartsWith, OperatorEndsWith,
+			OperatorEqual, OperatorNotEqual, OperatorMatch, OperatorNotMatch, OperatorGlob, OperatorLike, OperatorFuzzy,
+			OperatorIn, OperatorNotIn, OperatorExists, OperatorNotExists, OperatorIsEmpty:
+			return true
 		}
-		if operator == OperatorGreaterThan || operator == OperatorGreaterThanOrEqual {
-			return false
+	case ValueTypeIP:
+		switch operator {
+		case OperatorEqual, OperatorNotEqual, OperatorInCIDR, OperatorNotInCIDR, OperatorExists, OperatorNotExists:
+			return true
+		}
+	case ValueTypeSemver:
+		switch operator {
+		case OperatorEqual, OperatorNotEqual, OperatorLessThan, OperatorLessThanOrEqual, OperatorGreaterThan,
+			OperatorGreaterThanOrEqual, OperatorBetween, OperatorCaret, OperatorTilde, OperatorExists, OperatorNotExists:
+			return true
+		}
+	case ValueTypeBool:
+		switch operator {
+		case OperatorEqual, OperatorNotEqual, OperatorExists, OperatorNotExists:
+			return true
 		}
 	}
-	return true
+	return false
+}
+
+// filtValue applies the filter to a value read by a DataGetter, where ok reports whether the value was found.
+// Exists and NotExists only look at ok, and every other operator fails on a missing value.
+func (f Filter[T]) filtValue(data T, ok bool) bool {
+	switch f.operator {
+	case OperatorExists:
+		return ok
+	case OperatorNotExists:
+		return !ok
+	}
+	if !ok {
+		return false
+	}
+	return f.filtData(data)
 }
 
 // filtData applies the filter's operator to compare the filter's value with the provided data.
@@ -113,13 +535,50 @@ func validateOperator(operator Operator, valueType ValueType) bool {
 // Returns:
 // - bool: True if the data satisfies the filter condition, otherwise false.
 func (f Filter[T]) filtData(data T) bool {
+	value := f.value
+	// a pattern applies the match mode itself, see compilePattern
+	if f.mode != MatchCaseSensitive && !usesPattern(f.operator) {
+		value = any(f.normalized).(T)
+		data = any(normalizeString(any(data).(string), f.mode)).(T)
+	}
+
 	switch f.operator {
 	case OperatorEqual:
-		return filtEqual(f.value, data)
+		return filtEqual(value, data)
 	case OperatorNotEqual:
-		return !filtEqual(f.value, data)
+		return !filtEqual(value, data)
 	case OperatorContain:
-		return filtContains(f.value, data)
+		return filtContains(value, data)
+	case OperatorNotContain:
+		return !filtContains(value, data)
+	case OperatorStartsWith:
+		return filtStartsWith(value, data)
+	case OperatorEndsWith:
+		return filtEndsWith(value, data)
+	case OperatorMatch:
+		return f.filtMatch(data)
+	case OperatorNotMatch:
+		return !f.filtMatch(data)
+	case OperatorGlob, OperatorLike:
+		return f.filtMatch(data)
+	case OperatorFuzzy:
+		return f.filtFuzzy(any(value).(string), any(data).(string))
+	case OperatorIn:
+		return f.filtIn(data)
+	case OperatorNotIn:
+		return !f.filtIn(data)
+	case OperatorInCIDR:
+		return f.filtCIDR(data)
+	case OperatorNotInCIDR:
+		return !f.filtCIDR(data)
+	case OperatorBetween, OperatorCaret, OperatorTilde:
+		return f.filtBetween(data)
+	case OperatorExists:
+		return true
+	case OperatorNotExists:
+		return false
+	case OperatorIsEmpty:
+		return any(data).(string) == ""
 	case OperatorLessThan:
 		return compareComparable(f.value, data, OperatorLessThan)
 	case OperatorLessThanOrEqual:
@@ -149,6 +608,10 @@ func filtEqual[T Value](filterValue, data T) bool {
 			return true
 		}
 		return false
+	case int64:
+		return v == any(data).(int64)
+	case uint64:
+		return v == any(data).(uint64)
 	case float32:
 		fv := float64(v)
 		floatedData := float64(any(data).(float32))
@@ -163,12 +626,7 @@ func filtEqual[T Value](filterValue, data T) bool {
 		}
 		return false
 	case time.Time:
-		fv := any(data).(time.Time)
-		timedData := any(data).(time.Time)
-		if fv.Equal(timedData) {
-			return true
-		}
-		return false
+		return v.Equal(any(data).(time.Time))
 	case string:
 		fv := any(filterValue).(string)
 		searchTarget := any(data).(string)
@@ -176,6 +634,16 @@ func filtEqual[T Value](filterValue, data T) bool {
 			return true
 		}
 		return false
+	case bool:
+		return v == any(data).(bool)
+	case time.Duration:
+		return v == any(data).(time.Duration)
+	case netip.Addr:
+		return v.Unmap() == any(data).(netip.Addr).Unmap()
+	case Semver:
+		return any(data).(Semver).Compare(v) == 0
+	case Decimal:
+		return v == any(data).(Decimal)
 	}
 	return false
 }
@@ -196,6 +664,101 @@ func filtContains[T Value](filterValue, data T) bool {
 	return false
 }
 
+// filtStartsWith checks if the data string begins with the filter value.
+// Parameters:
+// - filterValue: The prefix to look for.
+// - data: The string to be checked.
+// Returns:
+// - bool: True if the data starts with the filter value, otherwise false.
+func filtStartsWith[T Value](filterValue, data T) bool {
+	return strings.HasPrefix(any(data).(string), any(filterValue).(string))
+}
+
+// filtEndsWith checks if the data string ends with the filter value.
+// Parameters:
+// - filterValue: The suffix to look for.
+// - data: The string to be checked.
+// Returns:
+// - bool: True if the data ends with the filter value, otherwise false.
+func filtEndsWith[T Value](filterValue, data T) bool {
+	return strings.HasSuffix(any(data).(string), any(filterValue).(string))
+}
+
+// filtMatch checks if the data string matches the filter's regular expression, glob or LIKE pattern.
+// Filters built without NewFilter have no compiled pattern, so it is compiled here instead.
+func (f Filter[T]) filtMatch(data T) bool {
+	pattern := f.pattern
+	if pattern == nil {
+		var err error
+		if pattern, err = f.compile(); err != nil {
+			return false
+		}
+	}
+
+	text := any(data).(string)
+	if f.mode&MatchIgnoreAccents != 0 {
+		text = removeAccents(text)
+	}
+	return pattern.MatchString(text)
+}
+
+// filtIn checks if the data is one of the values of the filter.
+// The data has already been normalized for the filter's mode by filtData.
+// Filters built without NewFilter or NewSetFilter have no set, so their values are scanned instead.
+func (f Filter[T]) filtIn(data T) bool {
+	if f.set == nil {
+		for _, value := range f.values {
+			if f.normalize(value) == data {
+				return true
+			}
+		}
+		return false
+	}
+	_, ok := f.set[data]
+	return ok
+}
+
+// filtBetween checks if the data lies between the lower and upper end of the filter, honoring its bounds.
+func (f Filter[T]) filtBetween(data T) bool {
+	lower, upper := OperatorGreaterThanOrEqual, OperatorLessThanOrEqual
+	if f.bounds&BoundsExcludeLower != 0 {
+		lower = OperatorGreaterThan
+	}
+	if f.bounds&BoundsExcludeUpper != 0 {
+		upper = OperatorLessThan
+	}
+	return compareComparable(f.value, data, lower) && compareComparable(f.upper, data, upper)
+}
+
+// compile compiles the value of a Match, Glob or Like filter into a regular expression for the filter's mode.
+func (f Filter[T]) compile() (*regexp.Regexp, error) {
+	value := any(f.value).(string)
+	switch f.operator {
+	case OperatorGlob:
+		expr, err := globToRegexp(value)
+		if err != nil {
+			return nil, err
+		}
+		value = expr
+	case OperatorLike:
+		value = likeToRegexp(value)
+	}
+	return compilePattern(value, f.mode)
+}
+
+// compilePattern compiles a regular expression for the given match mode.
+// Case is ignored with the (?i) flag rather than by folding, so character classes such as [a-z] keep working,
+// and accents are removed from the pattern as they are from the data.
+func compilePattern(pattern string, mode MatchMode) (*regexp.Regexp, error) {
+	if mode&MatchIgnoreAccents != 0 {
+		pattern = removeAccents(pattern)
+	}
+	if mode&MatchIgnoreCase != 0 {
+		pattern = "(?i)" + pattern
+	}
+	return regexp.Compile(pattern)
+}
+
 // compareComparable compares two comparable values based on the specified operator.
 // It handles comparison operators like LessThan, LessThanOrEqual, GreaterThan, and GreaterThanOrEqual for numbers and time values.
 // Parameters:
@@ -207,9 +770,11 @@ func filtContains[T Value](filterValue, data T) bool {
 func compareComparable[T Value](filterValue, data T, operator Operator) bool {
 	switch v := any(filterValue).(type) {
 	case int:
-		fv := float64(v)
-		floatedData := float64(any(data).(int))
-		return compareFloat64(operator, fv, floatedData)
+		return compareOrdered(operator, v, any(data).(int))
+	case int64:
+		return compareOrdered(operator, v, any(data).(int64))
+	case uint64:
+		return compareOrdered(operator, v, any(data).(uint64))
 	case float32:
 		fv := float64(v)
 		floatedData := float64(any(data).(float32))
@@ -217,6 +782,12 @@ func compareComparable[T Value](filterValue, data T, operator Operator) bool {
 	case float64:
 		floatedData := any(data).(float64)
 		return compareFloat64(operator, v, floatedData)
+	case time.Duration:
+		return compareOrdered(operator, v, any(data).(time.Duration))
+	case Semver:
+		return compareResult(operator, any(data).(Semver).Compare(v))
+	case Decimal:
+		return compareResult(operator, any(data).(Decimal).Compare(v))
 	case time.Time:
 		fv := any(filterValue).(time.Time)
 		timedData := any(data).(time.Time)
@@ -259,6 +830,31 @@ func compareFloat64(operator Operator, filterValue, data float64) bool {
 	return false
 }
 
+// compareOrdered compares data with the filter value exactly, for values that need no float tolerance.
+func compareOrdered[N cmp.Ordered](operator Operator, filterValue, data N) bool {
+	return compareResult(operator, cmp.Compare(data, filterValue))
+}
+
+// compareResult applies a comparison operator to c, the result of comparing data with the filter value,
+// which is negative, zero or positive as data is less than, equal to or greater than the value.
+func compareResult(operator Operator, c int) bool {
+	switch operator {
+	case OperatorEqual:
+		return c == 0
+	case OperatorNotEqual:
+		return c != 0
+	case OperatorLessThan:
+		return c < 0
+	case OperatorLessThanOrEqual:
+		return c <= 0
+	case OperatorGreaterThan:
+		return c > 0
+	case OperatorGreaterThanOrEqual:
+		return c >= 0
+	}
+	return false
+}
+
 // approximatelyEqual determines if two floating-point numbers are approximately equal.
 // Due to the precision limitations of floating-point types, direct equality checks can be unreliable.
 // This helper function checks if the two numbers are within a small threshold of each other.
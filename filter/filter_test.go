@@ -436,3 +436,428 @@ func TestFilter_filtData(t *testing.T) {
 		})
 	}
 }
+
+func TestFilter_Match(t *testing.T) {
+	tests := []struct {
+		name     string
+		operator Operator
+		pattern  string
+		data     string
+		want     bool
+	}{
+		{name: "Match request ID", operator: OperatorMatch, pattern: `req-[0-9a-f]{8}`, data: "handled req-1a2b3c4d in 3ms", want: true},
+		{name: "Match anchored error code", operator: OperatorMatch, pattern: `^E[0-9]{3}:`, data: "E404: not found", want: true},
+		{name: "Failed Match", operator: OperatorMatch, pattern: `^E[0-9]{3}:`, data: "W404: not found", want: false},
+		{name: "NotMatch", operator: OperatorNotMatch, pattern: `debug|trace`, data: "error: disk full", want: true},
+		{name: "Failed NotMatch", operator: OperatorNotMatch, pattern: `debug|trace`, data: "trace: entering", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := mustNewFilter(tt.operator, ValueTypeString, tt.pattern)
+			assert.Equal(t, tt.want, f.filtData(tt.data))
+
+			// a filter literal has no compiled pattern and must still match
+			literal := Filter[string]{operator: tt.operator, valueType: ValueTypeString, value: tt.pattern}
+			assert.Equal(t, tt.want, literal.filtData(tt.data))
+		})
+	}
+}
+
+func TestFilter_Match_Validate(t *testing.T) {
+	_, err := NewFilter(OperatorMatch, ValueTypeString, `req-[0-9`)
+	assert.ErrorIs(t, err, ErrInvalidPattern)
+
+	_, err = NewFilter(OperatorNotMatch, ValueTypeString, `(unclosed`)
+	assert.ErrorIs(t, err, ErrInvalidPattern)
+
+	_, err = NewFilter(OperatorMatch, ValueTypeNumber, 3)
+	assert.ErrorIs(t, err, ErrInvalidOperator)
+
+	_, err = NewFilter(OperatorMatch, ValueTypeDatetime, time.Now())
+	assert.ErrorIs(t, err, ErrInvalidOperator)
+}
+
+func TestFilter_PrefixSuffix(t *testing.T) {
+	tests := []struct {
+		name     string
+		operator Operator
+		value    string
+		data     string
+		want     bool
+	}{
+		{name: "StartsWith path", operator: OperatorStartsWith, value: "/api/v2/", data: "/api/v2/users/42", want: true},
+		{name: "Failed StartsWith path", operator: OperatorStartsWith, value: "/api/v2/", data: "/static/api/v2/", want: false},
+		{name: "EndsWith extension", operator: OperatorEndsWith, value: ".png", data: "/static/logo.png", want: true},
+		{name: "Failed EndsWith extension", operator: OperatorEndsWith, value: ".png", data: "/static/logo.png.gz", want: false},
+		{name: "NotContain", operator: OperatorNotContain, value: "debug", data: "error: disk full", want: true},
+		{name: "Failed NotContain", operator: OperatorNotContain, value: "debug", data: "[debug] entering", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := mustNewFilter(tt.operator, ValueTypeString, tt.value)
+			assert.Equal(t, tt.want, f.filtData(tt.data))
+		})
+	}
+
+	for _, operator := range []Operator{OperatorStartsWith, OperatorEndsWith, OperatorNotContain} {
+		_, err := NewFilter(operator, ValueTypeNumber, 3)
+		assert.ErrorIs(t, err, ErrInvalidOperator)
+	}
+}
+
+func TestFilter_MatchMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		operator Operator
+		mode     MatchMode
+		value    string
+		data     string
+		want     bool
+	}{
+		{name: "Case sensitive by default", operator: OperatorContain, mode: MatchCaseSensitive, value: "Error", data: "ERROR: disk full", want: false},
+		{name: "Contain ignoring case", operator: OperatorContain, mode: MatchIgnoreCase, value: "Error", data: "ERROR: disk full", want: true},
+		{name: "Equal ignoring case", operator: OperatorEqual, mode: MatchIgnoreCase, value: "ÉTÉ", data: "été", want: true},
+		{name: "NotEqual ignoring case", operator: OperatorNotEqual, mode: MatchIgnoreCase, value: "Beograd", data: "BEOGRAD", want: false},
+		{name: "Kelvin sign folds to K", operator: OperatorEqual, mode: MatchIgnoreCase, value: "K", data: "k", want: true},
+		{name: "StartsWith ignoring case", operator: OperatorStartsWith, mode: MatchIgnoreCase, value: "/API/", data: "/api/v2", want: true},
+		{name: "EndsWith ignoring case", operator: OperatorEndsWith, mode: MatchIgnoreCase, value: ".PNG", data: "logo.png", want: true},
+		{name: "NotContain ignoring case", operator: OperatorNotContain, mode: MatchIgnoreCase, value: "debug", data: "[DEBUG] entering", want: false},
+		{name: "Accents are kept when ignoring case", operator: OperatorEqual, mode: MatchIgnoreCase, value: "cafe", data: "CAFÉ", want: false},
+		{name: "Equal ignoring accents", operator: OperatorEqual, mode: MatchIgnoreAccents, value: "Mulic", data: "Mulić", want: true},
+		{name: "Composed and decomposed accents", operator: OperatorEqual, mode: MatchIgnoreAccents, value: "café", data: "café", want: true},
+		{name: "Case is kept when ignoring accents", operator: OperatorEqual, mode: MatchIgnoreAccents, value: "cafe", data: "CAFÉ", want: false},
+		{name: "Ignoring case and accents", operator: OperatorContain, mode: MatchIgnoreCase | MatchIgnoreAccents, value: "dordevic", data: "Đorđević", want: true},
+		{name: "Match ignoring case keeps character classes", operator: OperatorMatch, mode: MatchIgnoreCase, value: "^e[0-9]+$", data: "E404", want: true},
+		{name: "Match ignoring accents", operator: OperatorMatch, mode: MatchIgnoreAccents, value: "^Sr?bija$", data: "Šrbija", want: true},
+		{name: "NotMatch ignoring case", operator: OperatorNotMatch, mode: MatchIgnoreCase, value: "debug|trace", data: "TRACE", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := mustNewFilter(tt.operator, ValueTypeString, tt.value).WithMatchMode(tt.mode)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, f.filtData(tt.data))
+		})
+	}
+}
+
+func TestFilter_MatchMode_Validate(t *testing.T) {
+	_, err := mustNewFilter(OperatorEqual, ValueTypeNumber, 3).WithMatchMode(MatchIgnoreCase)
+	assert.ErrorIs(t, err, ErrInvalidMatchMode)
+
+	_, err = mustNewFilter(OperatorEqual, ValueTypeString, "a").WithMatchMode(MatchMode(8))
+	assert.ErrorIs(t, err, ErrInvalidMatchMode)
+
+	f, err := mustNewFilter(OperatorEqual, ValueTypeString, "a").WithMatchMode(MatchIgnoreCase)
+	assert.NoError(t, err)
+	assert.Equal(t, MatchIgnoreCase, f.MatchMode())
+	assert.False(t, f.equal(mustNewFilter(OperatorEqual, ValueTypeString, "a")))
+}
+
+func TestFilter_In(t *testing.T) {
+	ids, err := NewSetFilter(OperatorIn, ValueTypeString, []string{"c-101", "c-204", "c-330"})
+	assert.NoError(t, err)
+	assert.True(t, ids.filtData("c-204"))
+	assert.False(t, ids.filtData("c-205"))
+	assert.Equal(t, []string{"c-101", "c-204", "c-330"}, ids.Values())
+
+	notIn, err := NewSetFilter(OperatorNotIn, ValueTypeNumber, []int{1, 2, 3})
+	assert.NoError(t, err)
+	assert.False(t, notIn.filtData(2))
+	assert.True(t, notIn.filtData(4))
+
+	floats, err := NewSetFilter(OperatorIn, ValueTypeNumber, []float64{0.5, 1.5})
+	assert.NoError(t, err)
+	assert.True(t, floats.filtData(1.5))
+	assert.False(t, floats.filtData(2.5))
+
+	empty, err := NewSetFilter(OperatorIn, ValueTypeString, []string{})
+	assert.NoError(t, err)
+	assert.False(t, empty.filtData(""))
+
+	single := mustNewFilter(OperatorIn, ValueTypeString, "banana")
+	assert.True(t, single.filtData("banana"))
+	assert.Equal(t, []string{"banana"}, single.Values())
+
+	folded, err := ids.WithMatchMode(MatchIgnoreCase)
+	assert.NoError(t, err)
+	assert.True(t, folded.filtData("C-330"))
+
+	// a literal filter has no set and scans its values
+	literal := Filter[string]{operator: OperatorIn, valueType: ValueTypeString, values: []string{"a", "b"}}
+	assert.True(t, literal.filtData("b"))
+	assert.False(t, literal.filtData("c"))
+}
+
+func TestFilter_In_Validate(t *testing.T) {
+	_, err := NewSetFilter(OperatorEqual, ValueTypeString, []string{"a"})
+	assert.ErrorIs(t, err, ErrInvalidOperator)
+
+	_, err = NewSetFilter(OperatorIn, ValueTypeDatetime, []time.Time{time.Now()})
+	assert.ErrorIs(t, err, ErrInvalidOperator)
+
+	_, err = NewSetFilter(OperatorIn, ValueTypeString, []int{1})
+	assert.ErrorIs(t, err, ErrInvalidValueType)
+
+	info := mustNewFilter(OperatorNotIn, ValueTypeNumber, 7).Info()
+	assert.Nil(t, info.Value)
+	assert.Equal(t, []any{7}, info.Values)
+}
+
+func TestFilter_Between(t *testing.T) {
+	day := time.Date(2025, 3, 20, 0, 0, 0, 0, time.UTC)
+	nextDay := day.AddDate(0, 0, 1)
+
+	tests := []struct {
+		name   string
+		bounds Bounds
+		data   time.Time
+		want   bool
+	}{
+		{name: "Inside", bounds: BoundsInclusive, data: day.Add(12 * time.Hour), want: true},
+		{name: "Lower end included", bounds: BoundsInclusive, data: day, want: true},
+		{name: "Upper end included", bounds: BoundsInclusive, data: nextDay, want: true},
+		{name: "Before", bounds: BoundsInclusive, data: day.Add(-time.Second), want: false},
+		{name: "After", bounds: BoundsInclusive, data: nextDay.Add(time.Second), want: false},
+		{name: "Upper end excluded", bounds: BoundsExcludeUpper, data: nextDay, want: false},
+		{name: "Lower end kept when excluding upper", bounds: BoundsExcludeUpper, data: day, want: true},
+		{name: "Lower end excluded", bounds: BoundsExcludeLower, data: day, want: false},
+		{name: "Both ends excluded", bounds: BoundsExclusive, data: nextDay, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewRangeFilter(ValueTypeDatetime, day, nextDay, tt.bounds)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, f.filtData(tt.data))
+		})
+	}
+
+	numbers, err := NewRangeFilter(ValueTypeNumber, 1.5, 2.5, BoundsInclusive)
+	assert.NoError(t, err)
+	assert.True(t, numbers.filtData(2.5))
+	assert.False(t, numbers.filtData(2.6))
+	assert.Equal(t, 1.5, numbers.Value())
+	assert.Equal(t, 2.5, numbers.Upper())
+
+	single := mustNewFilter(OperatorBetween, ValueTypeNumber, 3)
+	assert.True(t, single.filtData(3))
+	assert.False(t, single.filtData(4))
+}
+
+func TestFilter_Between_Validate(t *testing.T) {
+	_, err := NewRangeFilter(ValueTypeNumber, 5, 1, BoundsInclusive)
+	assert.ErrorIs(t, err, ErrInvalidRange)
+
+	_, err = NewRangeFilter(ValueTypeNumber, 1, 5, Bounds(4))
+	assert.ErrorIs(t, err, ErrInvalidRange)
+
+	_, err = NewRangeFilter(ValueTypeString, "a", "b", BoundsInclusive)
+	assert.ErrorIs(t, err, ErrInvalidOperator)
+
+	// an empty range is valid, it just never matches
+	empty, err := NewRangeFilter(ValueTypeNumber, 1, 1, BoundsExclusive)
+	assert.NoError(t, err)
+	assert.False(t, empty.filtData(1))
+
+	info := empty.Info()
+	assert.Equal(t, 1, info.Value)
+	assert.Equal(t, 1, info.Upper)
+	assert.Equal(t, BoundsExclusive, info.Bounds)
+}
+
+func TestFilter_Presence_Validate(t *testing.T) {
+	_, err := NewPresenceFilter[int](OperatorExists, ValueTypeNumber)
+	assert.NoError(t, err)
+
+	_, err = NewPresenceFilter[time.Time](OperatorNotExists, ValueTypeDatetime)
+	assert.NoError(t, err)
+
+	_, err = NewPresenceFilter[int](OperatorIsEmpty, ValueTypeNumber)
+	assert.ErrorIs(t, err, ErrInvalidOperator)
+
+	_, err = NewPresenceFilter[string](OperatorEqual, ValueTypeString)
+	assert.ErrorIs(t, err, ErrInvalidOperator)
+
+	_, err = NewPresenceFilter[int](OperatorExists, ValueTypeString)
+	assert.ErrorIs(t, err, ErrInvalidValueType)
+}
+
+func TestFilter_Glob(t *testing.T) {
+	tests := []struct {
+		name     string
+		operator Operator
+		pattern  string
+		data     string
+		want     bool
+	}{
+		{name: "Star in the middle", operator: OperatorGlob, pattern: "*timeout*", data: "read timeout after 3s", want: true},
+		{name: "Star matches nothing", operator: OperatorGlob, pattern: "*timeout*", data: "timeout", want: true},
+		{name: "Glob is anchored", operator: OperatorGlob, pattern: "timeout", data: "read timeout", want: false},
+		{name: "Question marks", operator: OperatorGlob, pattern: "api-??-prod", data: "api-eu-prod", want: true},
+		{name: "Question mark needs a character", operator: OperatorGlob, pattern: "api-??-prod", data: "api-e-prod", want: false},
+		{name: "Question mark matches a rune", operator: OperatorGlob, pattern: "caf?", data: "café", want: true},
+		{name: "Character range", operator: OperatorGlob, pattern: "node[0-9]", data: "node7", want: true},
+		{name: "Negated class", operator: OperatorGlob, pattern: "node[!0-9]", data: "node7", want: false},
+		{name: "Caret negates too", operator: OperatorGlob, pattern: "node[^0-9]", data: "nodeX", want: true},
+		{name: "Closing bracket first in class", operator: OperatorGlob, pattern: "[]a]", data: "]", want: true},
+		{name: "Regexp characters are literal", operator: OperatorGlob, pattern: "a.b+(c)", data: "a.b+(c)", want: true},
+		{name: "Dot is not a wildcard", operator: OperatorGlob, pattern: "a.b", data: "axb", want: false},
+		{name: "Escaped star", operator: OperatorGlob, pattern: `\*x`, data: "*x", want: true},
+		{name: "Escaped star is not a wildcard", operator: OperatorGlob, pattern: `\*x`, data: "ax", want: false},
+		{name: "Star spans lines", operator: OperatorGlob, pattern: "a*b", data: "a\nb", want: true},
+		{name: "Like percent", operator: OperatorLike, pattern: "%timeout%", data: "read timeout after 3s", want: true},
+		{name: "Like underscore", operator: OperatorLike, pattern: "api-__-prod", data: "api-us-prod", want: true},
+		{name: "Like is anchored", operator: OperatorLike, pattern: "api%", data: "the api", want: false},
+		{name: "Like keeps star literal", operator: OperatorLike, pattern: "a*", data: "abc", want: false},
+		{name: "Like escaped percent", operator: OperatorLike, pattern: `100\%`, data: "100%", want: true},
+		{name: "Like brackets are literal", operator: OperatorLike, pattern: "[a]%", data: "[a]x", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := mustNewFilter(tt.operator, ValueTypeString, tt.pattern)
+			assert.Equal(t, tt.want, f.filtData(tt.data))
+
+			// a filter literal has no compiled pattern and must still match
+			literal := Filter[string]{operator: tt.operator, valueType: ValueTypeString, value: tt.pattern}
+			assert.Equal(t, tt.want, literal.filtData(tt.data))
+		})
+	}
+}
+
+func TestFilter_Glob_MatchMode(t *testing.T) {
+	f, err := mustNewFilter(OperatorGlob, ValueTypeString, "*ERROR*").WithMatchMode(MatchIgnoreCase)
+	assert.NoError(t, err)
+	assert.True(t, f.filtData("an error occurred"))
+
+	f, err = mustNewFilter(OperatorLike, ValueTypeString, "Mulic%").WithMatchMode(MatchIgnoreAccents)
+	assert.NoError(t, err)
+	assert.True(t, f.filtData("Mulić, Fejsal"))
+}
+
+func TestFilter_Glob_Validate(t *testing.T) {
+	_, err := NewFilter(OperatorGlob, ValueTypeString, "node[0-9")
+	assert.ErrorIs(t, err, ErrInvalidPattern)
+
+	_, err = NewFilter(OperatorGlob, ValueTypeString, "[]")
+	assert.ErrorIs(t, err, ErrInvalidPattern)
+
+	_, err = NewFilter(OperatorLike, ValueTypeNumber, 3)
+	assert.ErrorIs(t, err, ErrInvalidOperator)
+}
+
+func TestFilter_Bool(t *testing.T) {
+	equal := mustNewFilter(OperatorEqual, ValueTypeBool, true)
+	assert.True(t, equal.filtData(true))
+	assert.False(t, equal.filtData(false))
+
+	notEqual := mustNewFilter(OperatorNotEqual, ValueTypeBool, true)
+	assert.False(t, notEqual.filtData(true))
+	assert.True(t, notEqual.filtData(false))
+
+	exists, err := NewPresenceFilter[bool](OperatorExists, ValueTypeBool)
+	assert.NoError(t, err)
+	assert.True(t, exists.filtValue(false, true))
+	assert.False(t, exists.filtValue(false, false))
+}
+
+func TestFilter_Bool_Validate(t *testing.T) {
+	_, err := NewFilter(OperatorLessThan, ValueTypeBool, true)
+	assert.ErrorIs(t, err, ErrInvalidOperator)
+
+	_, err = NewSetFilter(OperatorIn, ValueTypeBool, []bool{true})
+	assert.ErrorIs(t, err, ErrInvalidOperator)
+
+	_, err = NewFilter(OperatorEqual, ValueTypeString, true)
+	assert.ErrorIs(t, err, ErrInvalidValueType)
+
+	_, err = NewFilter(OperatorEqual, ValueTypeBool, "true")
+	assert.ErrorIs(t, err, ErrInvalidValueType)
+
+	_, err = NewPresenceFilter[bool](OperatorIsEmpty, ValueTypeBool)
+	assert.ErrorIs(t, err, ErrInvalidOperator)
+}
+
+func TestFilter_Duration(t *testing.T) {
+	limit := 500 * time.Millisecond
+	tests := []struct {
+		name     string
+		operator Operator
+		data     time.Duration
+		want     bool
+	}{
+		{name: "Greater than", operator: OperatorGreaterThan, data: 501 * time.Millisecond, want: true},
+		{name: "Not greater than", operator: OperatorGreaterThan, data: limit, want: false},
+		{name: "Greater than or equal", operator: OperatorGreaterThanOrEqual, data: limit, want: true},
+		{name: "Less than", operator: OperatorLessThan, data: 499 * time.Millisecond, want: true},
+		{name: "Less than or equal", operator: OperatorLessThanOrEqual, data: 500*time.Millisecond + time.Nanosecond, want: false},
+		// durations are exact, unlike floats which tolerate tiny differences
+		{name: "Equal", operator: OperatorEqual, data: 500*time.Millisecond + time.Nanosecond, want: false},
+		{name: "Not equal", operator: OperatorNotEqual, data: limit, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := mustNewFilter(tt.operator, ValueTypeDuration, limit)
+			assert.Equal(t, tt.want, f.filtData(tt.data))
+		})
+	}
+
+	between, err := NewRangeFilter(ValueTypeDuration, 100*time.Millisecond, time.Second, BoundsExcludeUpper)
+	assert.NoError(t, err)
+	assert.True(t, between.filtData(100*time.Millisecond))
+	assert.False(t, between.filtData(time.Second))
+
+	in, err := NewSetFilter(OperatorIn, ValueTypeDuration, []time.Duration{time.Second, time.Minute})
+	assert.NoError(t, err)
+	assert.True(t, in.filtData(60*time.Second))
+	assert.False(t, in.filtData(2*time.Second))
+
+	_, err = NewFilter(OperatorContain, ValueTypeDuration, time.Second)
+	assert.ErrorIs(t, err, ErrInvalidOperator)
+	_, err = NewFilter(OperatorEqual, ValueTypeNumber, time.Second)
+	assert.ErrorIs(t, err, ErrInvalidValueType)
+	_, err = NewRangeFilter(ValueTypeDuration, time.Second, time.Millisecond, BoundsInclusive)
+	assert.ErrorIs(t, err, ErrInvalidRange)
+}
+
+func TestFilter_Int64(t *testing.T) {
+	// 2^53 + 1 is the first integer a float64 cannot hold, so it would compare equal to 2^53
+	const id int64 = 1<<53 + 1
+
+	equal := mustNewFilter(OperatorEqual, ValueTypeNumber, id)
+	assert.True(t, equal.filtData(id))
+	assert.False(t, equal.filtData(id-1))
+
+	greater := mustNewFilter(OperatorGreaterThan, ValueTypeNumber, id-1)
+	assert.True(t, greater.filtData(id))
+	assert.False(t, greater.filtData(id-1))
+
+	lessOrEqual := mustNewFilter(OperatorLessThanOrEqual, ValueTypeNumber, int(id))
+	assert.True(t, lessOrEqual.filtData(int(id)))
+	assert.False(t, lessOrEqual.filtData(int(id+1)))
+
+	in, err := NewSetFilter(OperatorIn, ValueTypeNumber, []int64{id, -id})
+	assert.NoError(t, err)
+	assert.True(t, in.filtData(-id))
+	assert.False(t, in.filtData(id+1))
+}
+
+func TestFilter_Uint64(t *testing.T) {
+	const top uint64 = 1<<64 - 1
+
+	equal := mustNewFilter(OperatorEqual, ValueTypeNumber, top)
+	assert.True(t, equal.filtData(top))
+	assert.False(t, equal.filtData(top-1))
+
+	between, err := NewRangeFilter(ValueTypeNumber, top-10, top, BoundsExcludeUpper)
+	assert.NoError(t, err)
+	assert.True(t, between.filtData(top-1))
+	assert.False(t, between.filtData(top))
+
+	_, err = NewFilter(OperatorEqual, ValueTypeString, top)
+	assert.ErrorIs(t, err, ErrInvalidValueType)
+}
@@ -1,5 +1,7 @@
 package filter
 
+import "sync"
+
 type Filterable interface {
 	filt() bool
 }
@@ -7,6 +9,15 @@ type Filterable interface {
 // FSet implements the Filterable interface which allows it to be used in the FTree.
 // FSet is a generic type that holds a value and a set of filters that can be applied to the value.
 // It also has a condition field that determines whether the filters should be evaluated using an AND/OR logic.
+// When the DataGetter reports the value as missing, only Exists/NotExists filters can match.
+// Key optionally records which field the DataGetter reads; it is not used for filtering,
+// but lets the set be described (see Describe) and turned back into text or JSON.
+// Format optionally records how the DataGetter parses the field, such as a time layout.
+// Optimize only merges sets with equal Key and Format, as only those are known to read the same value.
+//
+// A set created by NewFilterSet with many Contain filters under ConditionOr, or many NotContain filters
+// under ConditionAnd, checks all of them in a single scan of the value (see containIndex),
+// so its Filters must not be changed afterwards.
 //
 // Example Usage:
 /*
@@ -24,10 +35,49 @@ type FSet[T Value] struct {
 	DataGetter func() (T, bool)
 	Filters    []Filter[T]
 	Condition  Condition
+	Key        any
+	Format     any
+
+	index *containIndex[T]
+}
+
+// Describer is implemented by filter sets that can report their contents without exposing their type parameter.
+type Describer interface {
+	Describe() SetInfo
+}
+
+// SetInfo describes a filter set independently of its value type.
+type SetInfo struct {
+	Key       any
+	Format    any // how the DataGetter parses the key, see FSet.Format
+	Condition Condition
+	Filters   []FilterInfo
+}
+
+// FilterInfo describes a single filter independently of its value type.
+type FilterInfo struct {
+	Operator  Operator
+	ValueType ValueType
+	Value     any
+	Values    []any // members of an In/NotIn filter or prefixes of an InCIDR/NotInCIDR filter, whose Value is nil
+	Upper     any   // upper end of a Between filter, whose Value is the lower end
+	Bounds    Bounds
+	Distance  int // maximum edit distance of a Fuzzy filter
+	FuzzyMode FuzzyMode
+	MatchMode MatchMode
 }
 
 func NewFilterSet[T Value](dataGetter func() (T, bool), filters []Filter[T], condition Condition) FSet[T] {
-	return FSet[T]{DataGetter: dataGetter, Filters: filters, Condition: condition}
+	return FSet[T]{DataGetter: dataGetter, Filters: filters, Condition: condition, index: &containIndex[T]{}}
+}
+
+// Describe implements the Describer interface.
+func (f FSet[T]) Describe() SetInfo {
+	info := SetInfo{Key: f.Key, Format: f.Format, Condition: f.Condition, Filters: make([]FilterInfo, len(f.Filters))}
+	for i, filter := range f.Filters {
+		info.Filters[i] = filter.Info()
+	}
+	return info
 }
 
 func (f FSet[T]) filt() bool {
@@ -36,15 +86,28 @@ func (f FSet[T]) filt() bool {
 	}
 
 	data, ok := f.DataGetter()
-	if !ok {
-		return false
-	}
 
 	hasFiltered := false
-	allFiltered := true
+	// a set without filters matches any present value, but never a missing one
+	allFiltered := ok || len(f.Filters) > 0
+
+	filters := f.Filters
+	if f.index != nil {
+		if index := f.index.load(f.Filters, f.Condition); len(index.groups) > 0 {
+			// the covered filters all match or all fail together, as a single filter would under the condition
+			filtered := ok && index.contains(data) == (f.Condition == ConditionOr)
+			if filtered == (f.Condition == ConditionOr) {
+				return filtered
+			}
+			if !filtered {
+				allFiltered = false
+			}
+			filters = index.rest
+		}
+	}
 
-	for _, filter := range f.Filters {
-		filtered := filter.filtData(data)
+	for _, filter := range filters {
+		filtered := filter.filtValue(data, ok)
 		hasFiltered = filtered
 		if f.Condition == ConditionOr {
 			if hasFiltered {
@@ -59,3 +122,81 @@ func (f FSet[T]) filt() bool {
 
 	return allFiltered
 }
+
+// minIndexedFilters is the number of Contain (or NotContain) filters with the same match mode from which
+// a containIndex checks them with an automaton, which beats searching for each value separately.
+const minIndexedFilters = 16
+
+// containIndex checks the Contain filters of a set under ConditionOr, or its NotContain filters under ConditionAnd,
+// with one Aho-Corasick automaton per match mode, so the value is scanned once instead of once per filter.
+// It is built on the first use of the set, as Optimize creates many sets while merging that are never evaluated.
+type containIndex[T Value] struct {
+	once   sync.Once
+	groups []containGroup
+	rest   []Filter[T] // filters that no automaton checks
+}
+
+// containGroup is the automaton matching the values of the filters with one match mode.
+type containGroup struct {
+	mode      MatchMode
+	automaton *automaton
+}
+
+// load builds the index for filters evaluated with condition on its first call and returns it.
+func (x *containIndex[T]) load(filters []Filter[T], condition Condition) *containIndex[T] {
+	x.once.Do(func() {
+		var operator Operator
+		switch condition {
+		case ConditionOr:
+			operator = OperatorContain
+		case ConditionAnd:
+			operator = OperatorNotContain
+		default:
+			return
+		}
+
+		var modes []MatchMode
+		patterns := map[MatchMode][]string{}
+		for _, filter := range filters {
+			if filter.operator != operator {
+				continue
+			}
+			value, ok := any(filter.value).(string)
+			if !ok {
+				return
+			}
+			if filter.mode != MatchCaseSensitive {
+				value = filter.normalized
+			}
+			if _, seen := patterns[filter.mode]; !seen {
+				modes = append(modes, filter.mode)
+			}
+			patterns[filter.mode] = append(patterns[filter.mode], value)
+		}
+
+		indexed := map[MatchMode]bool{}
+		for _, mode := range modes {
+			if len(patterns[mode]) >= minIndexedFilters {
+				x.groups = append(x.groups, containGroup{mode: mode, automaton: newAutomaton(patterns[mode])})
+				indexed[mode] = true
+			}
+		}
+		for _, filter := range filters {
+			if filter.operator != operator || !indexed[filter.mode] {
+				x.rest = append(x.rest, filter)
+			}
+		}
+	})
+	return x
+}
+
+// contains reports whether data contains the value of any filter the index checks.
+func (x *containIndex[T]) contains(data T) bool {
+	text := any(data).(string)
+	for _, group := range x.groups {
+		if group.automaton.contains(normalizeString(text, group.mode)) {
+			return true
+		}
+	}
+	return false
+}
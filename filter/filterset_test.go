@@ -194,3 +194,66 @@ func mustNewFilter[T Value](operator Operator, valueType ValueType, value T) Fil
 	}
 	return f
 }
+
+func TestFSet_Describe(t *testing.T) {
+	fset := NewFilterSet(
+		func() (string, bool) { return "banana", true },
+		[]Filter[string]{
+			mustNewFilter(OperatorContain, ValueTypeString, "ana"),
+			mustNewFilter(OperatorNotEqual, ValueTypeString, "tomato"),
+		},
+		ConditionOr,
+	)
+	fset.Key = "fruit"
+
+	want := SetInfo{
+		Key:       "fruit",
+		Condition: ConditionOr,
+		Filters: []FilterInfo{
+			{Operator: OperatorContain, ValueType: ValueTypeString, Value: "ana"},
+			{Operator: OperatorNotEqual, ValueType: ValueTypeString, Value: "tomato"},
+		},
+	}
+	assert.Equal(t, want, fset.Describe())
+}
+
+func TestFSetFilt_Presence(t *testing.T) {
+	exists, err := NewPresenceFilter[string](OperatorExists, ValueTypeString)
+	assert.NoError(t, err)
+	missing, err := NewPresenceFilter[string](OperatorNotExists, ValueTypeString)
+	assert.NoError(t, err)
+	empty, err := NewPresenceFilter[string](OperatorIsEmpty, ValueTypeString)
+	assert.NoError(t, err)
+	contain := mustNewFilter(OperatorContain, ValueTypeString, "a")
+
+	found := func() (string, bool) { return "banana", true }
+	blank := func() (string, bool) { return "", true }
+	absent := func() (string, bool) { return "", false }
+
+	tests := []struct {
+		name       string
+		filters    []Filter[string]
+		condition  Condition
+		dataGetter func() (string, bool)
+		want       bool
+	}{
+		{name: "Exists on a present value", filters: []Filter[string]{exists}, condition: ConditionAnd, dataGetter: found, want: true},
+		{name: "Exists on a missing value", filters: []Filter[string]{exists}, condition: ConditionAnd, dataGetter: absent, want: false},
+		{name: "NotExists on a missing value", filters: []Filter[string]{missing}, condition: ConditionAnd, dataGetter: absent, want: true},
+		{name: "NotExists on an empty value", filters: []Filter[string]{missing}, condition: ConditionAnd, dataGetter: blank, want: false},
+		{name: "IsEmpty on an empty value", filters: []Filter[string]{empty}, condition: ConditionAnd, dataGetter: blank, want: true},
+		{name: "IsEmpty on a missing value", filters: []Filter[string]{empty}, condition: ConditionAnd, dataGetter: absent, want: false},
+		{name: "IsEmpty on a present value", filters: []Filter[string]{empty}, condition: ConditionAnd, dataGetter: found, want: false},
+		{name: "Missing or containing", filters: []Filter[string]{missing, contain}, condition: ConditionOr, dataGetter: absent, want: true},
+		{name: "Exists and containing", filters: []Filter[string]{exists, contain}, condition: ConditionAnd, dataGetter: found, want: true},
+		{name: "Comparison on a missing value", filters: []Filter[string]{mustNewFilter(OperatorNotEqual, ValueTypeString, "x")}, condition: ConditionAnd, dataGetter: absent, want: false},
+		{name: "No filters on a missing value", filters: nil, condition: ConditionAnd, dataGetter: absent, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fset := NewFilterSet(tt.dataGetter, tt.filters, tt.condition)
+			assert.Equal(t, tt.want, fset.filt())
+		})
+	}
+}
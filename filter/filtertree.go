@@ -4,6 +4,9 @@ package filter
 // Each node can either be a leaf node containing a filter set (FilterSet) or
 // an internal node with a logical condition (AND/OR) applied between two child nodes (Left and Right).
 //
+// Any node can be negated by setting Negate, which inverts the result of that node
+// (for a leaf, the result of its filter set; for an internal node, the result of its condition).
+//
 // Example Usage:
 /*
 Thus the FTree of
@@ -28,12 +31,18 @@ type FTree struct {
 	Left, Right *FTree
 	Condition   Condition
 	FilterSet   Filterable
+	Negate      bool
 }
 
 // Evaluate executes the filtering logic on the tree.
 // It recursively evaluates the left and right subtrees if it's an internal node,
 // or directly applies the filter set if it's a leaf node.
+// The result is inverted if the node is negated.
 func (ft *FTree) Evaluate() bool {
+	return ft.evaluate() != ft.Negate
+}
+
+func (ft *FTree) evaluate() bool {
 	if ft.FilterSet != nil {
 		return ft.FilterSet.filt()
 	}
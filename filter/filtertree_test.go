@@ -115,6 +115,43 @@ func TestFTree_Evaluate(t *testing.T) {
 			},
 			expected: true,
 		},
+		{
+			name: "Negated filtered single node -> Unfiltered",
+			ftree: &FTree{
+				FilterSet: mockFilterable{result: true},
+				Negate:    true,
+			},
+			expected: false,
+		},
+		{
+			name: "Not (Unfiltered Or Unfiltered) -> Filtered",
+			ftree: &FTree{
+				Left:      &FTree{FilterSet: mockFilterable{result: false}},
+				Right:     &FTree{FilterSet: mockFilterable{result: false}},
+				Condition: ConditionOr,
+				Negate:    true,
+			},
+			expected: true,
+		},
+		{
+			name: "Filtered And (Not Filtered) -> Unfiltered",
+			ftree: &FTree{
+				Left:      &FTree{FilterSet: mockFilterable{result: true}},
+				Right:     &FTree{FilterSet: mockFilterable{result: true}, Negate: true},
+				Condition: ConditionAnd,
+			},
+			expected: false,
+		},
+		{
+			name: "Not (Filtered And (Not Unfiltered)) -> Unfiltered",
+			ftree: &FTree{
+				Left:      &FTree{FilterSet: mockFilterable{result: true}},
+				Right:     &FTree{FilterSet: mockFilterable{result: false}, Negate: true},
+				Condition: ConditionAnd,
+				Negate:    true,
+			},
+			expected: false,
+		},
 	}
 
 	for _, tc := range tests {
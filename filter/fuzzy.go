@@ -0,0 +1,118 @@
+package filter
+
+import "strings"
+
+// FuzzyMode controls how a Fuzzy filter measures the distance between its value and the data.
+// Modes can be combined, e.g. FuzzyTranspositions | FuzzyTokens.
+type FuzzyMode uint8
+
+const (
+	// FuzzyLevenshtein counts inserted, deleted and substituted characters, which is the default.
+	FuzzyLevenshtein FuzzyMode = 0
+	// FuzzyTranspositions also counts swapping two adjacent characters as a single edit,
+	// so "Belgarde" is one edit away from "Belgrade" (optimal string alignment Damerau-Levenshtein distance).
+	FuzzyTranspositions FuzzyMode = 1 << 0
+	// FuzzyTokens matches if any run of words of the data, as many as the value has, is close enough,
+	// instead of comparing the value with the whole data.
+	FuzzyTokens FuzzyMode = 1 << 1
+
+	fuzzyModeAll = FuzzyTranspositions | FuzzyTokens
+)
+
+// FuzzyAuto picks the maximum distance of a Fuzzy filter from the length of its value:
+// no edits for up to 2 characters, 1 edit for up to 5 characters and 2 edits for longer values.
+const FuzzyAuto = -1
+
+// autoDistance returns the maximum distance FuzzyAuto allows for a value of n characters.
+func autoDistance(n int) int {
+	switch {
+	case n <= 2:
+		return 0
+	case n <= 5:
+		return 1
+	}
+	return 2
+}
+
+// filtFuzzy checks if the data is within the filter's edit distance of value.
+// Both have already been normalized for the filter's mode by filtData.
+func (f Filter[T]) filtFuzzy(value, data string) bool {
+	target := []rune(value)
+	distance := f.distance
+	if distance == FuzzyAuto {
+		distance = autoDistance(len(target))
+	}
+	transpositions := f.fuzzyMode&FuzzyTranspositions != 0
+
+	if f.fuzzyMode&FuzzyTokens == 0 {
+		return withinDistance(target, []rune(data), distance, transpositions)
+	}
+
+	words := strings.Fields(data)
+	n := max(len(strings.Fields(value)), 1)
+	for i := 0; i+n <= len(words); i++ {
+		if withinDistance(target, []rune(strings.Join(words[i:i+n], " ")), distance, transpositions) {
+			return true
+		}
+	}
+	return false
+}
+
+// withinDistance reports whether a and b are at most k edits apart.
+//
+// Only the diagonal band of width 2k+1 of the dynamic programming matrix can hold distances up to k,
+// so only that band is computed, and the computation stops as soon as a whole row exceeds k.
+// This bounds the cost to O(k·len(a)) however long b is, and strings whose lengths differ
+// by more than k are rejected without any work.
+func withinDistance(a, b []rune, k int, transpositions bool) bool {
+	n, m := len(a), len(b)
+	if n-m > k || m-n > k {
+		return false
+	}
+	if k == 0 {
+		return string(a) == string(b)
+	}
+
+	// distances are capped at k+1, which stands for "too far" and keeps the cells outside the band
+	tooFar := k + 1
+	prev2 := make([]int, m+1)
+	prev := make([]int, m+1)
+	cur := make([]int, m+1)
+	for j := range prev {
+		prev[j] = min(j, tooFar)
+	}
+
+	for i := 1; i <= n; i++ {
+		lo, hi := max(1, i-k), min(m, i+k)
+		// the cells just outside the band are the only ones of this row that the next rows read
+		if lo == 1 {
+			cur[0] = min(i, tooFar)
+		} else {
+			cur[lo-1] = tooFar
+		}
+		if hi < m {
+			cur[hi+1] = tooFar
+		}
+
+		rowMin := cur[lo-1]
+		for j := lo; j <= hi; j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d := min(prev[j-1]+cost, prev[j]+1, cur[j-1]+1)
+			if transpositions && i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d = min(d, prev2[j-2]+1)
+			}
+			cur[j] = min(d, tooFar)
+			rowMin = min(rowMin, cur[j])
+		}
+		if rowMin > k {
+			return false
+		}
+
+		prev2, prev, cur = prev, cur, prev2
+	}
+
+	return prev[m] <= k
+}
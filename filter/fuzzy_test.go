@@ -0,0 +1,115 @@
+package filter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// levenshtein is a plain full-matrix implementation that withinDistance is checked against.
+func levenshtein(a, b []rune, transpositions bool) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j-1]+cost, d[i-1][j]+1, d[i][j-1]+1)
+			if transpositions && i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+func TestWithinDistance(t *testing.T) {
+	words := []string{"", "a", "ab", "ba", "abc", "acb", "Belgrade", "Belgarde", "Beograd", "Belgrad", "kitten", "sitting", "ca", "abc d", "Đorđe"}
+
+	for _, a := range words {
+		for _, b := range words {
+			for _, transpositions := range []bool{false, true} {
+				want := levenshtein([]rune(a), []rune(b), transpositions)
+				for k := 0; k <= 4; k++ {
+					assert.Equal(t, want <= k, withinDistance([]rune(a), []rune(b), k, transpositions),
+						"distance(%q, %q) = %d, k = %d, transpositions = %v", a, b, want, k, transpositions)
+				}
+			}
+		}
+	}
+}
+
+func TestWithinDistance_LongData(t *testing.T) {
+	long := []rune(strings.Repeat("x", 1<<20))
+	assert.False(t, withinDistance([]rune("Belgrade"), long, 2, false))
+	assert.True(t, withinDistance(long, append([]rune("y"), long[1:]...), 1, false))
+}
+
+func TestFilter_Fuzzy(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		distance int
+		mode     FuzzyMode
+		data     string
+		want     bool
+	}{
+		{name: "Exact", value: "Belgrade", distance: 2, data: "Belgrade", want: true},
+		{name: "One substitution", value: "Belgrade", distance: 1, data: "Belgrahe", want: true},
+		{name: "Two edits over the limit", value: "Belgrade", distance: 1, data: "Belgrad!!", want: false},
+		{name: "Transposition is two edits", value: "Belgrade", distance: 1, data: "Belgarde", want: false},
+		{name: "Transposition is one edit with Damerau", value: "Belgrade", distance: 1, mode: FuzzyTranspositions, data: "Belgarde", want: true},
+		{name: "Whole data is compared", value: "Belgrade", distance: 2, data: "Hotel in Belgrade", want: false},
+		{name: "Any word with tokens", value: "Belgrade", distance: 1, mode: FuzzyTokens, data: "Hotel in Belgrde, Serbia", want: false},
+		{name: "Word without punctuation", value: "Belgrade", distance: 1, mode: FuzzyTokens, data: "Hotel in Belgrde Serbia", want: true},
+		{name: "Run of words", value: "Novi Sad", distance: 1, mode: FuzzyTokens, data: "from Novi Sda today", want: false},
+		{name: "Run of words with Damerau", value: "Novi Sad", distance: 1, mode: FuzzyTokens | FuzzyTranspositions, data: "from Novi Sda today", want: true},
+		{name: "Auto distance for short values", value: "ab", distance: FuzzyAuto, data: "ac", want: false},
+		{name: "Auto distance for medium values", value: "Nis", distance: FuzzyAuto, data: "Niš", want: true},
+		{name: "Auto distance for long values", value: "Kragujevac", distance: FuzzyAuto, data: "Kraguevc", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewFuzzyFilter(ValueTypeString, tt.value, tt.distance, tt.mode)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, f.filtData(tt.data))
+		})
+	}
+
+	f, err := NewFuzzyFilter(ValueTypeString, "Đorđević", 1, FuzzyLevenshtein)
+	assert.NoError(t, err)
+	f, err = f.WithMatchMode(MatchIgnoreCase | MatchIgnoreAccents)
+	assert.NoError(t, err)
+	assert.True(t, f.filtData("DJORDEVIC"))
+
+	auto := mustNewFilter(OperatorFuzzy, ValueTypeString, "Subotica")
+	assert.Equal(t, FuzzyAuto, auto.Distance())
+	assert.True(t, auto.filtData("Subotca"))
+}
+
+func TestFilter_Fuzzy_Validate(t *testing.T) {
+	_, err := NewFuzzyFilter(ValueTypeString, "a", -2, FuzzyLevenshtein)
+	assert.ErrorIs(t, err, ErrInvalidDistance)
+
+	_, err = NewFuzzyFilter(ValueTypeString, "a", 1, FuzzyMode(8))
+	assert.ErrorIs(t, err, ErrInvalidDistance)
+
+	_, err = NewFuzzyFilter(ValueTypeNumber, 1, 1, FuzzyLevenshtein)
+	assert.ErrorIs(t, err, ErrInvalidOperator)
+
+	f, err := NewFuzzyFilter(ValueTypeString, "a", 3, FuzzyTokens)
+	assert.NoError(t, err)
+	info := f.Info()
+	assert.Equal(t, 3, info.Distance)
+	assert.Equal(t, FuzzyTokens, info.FuzzyMode)
+}
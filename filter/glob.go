@@ -0,0 +1,111 @@
+package filter
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// globToRegexp translates a shell-style wildcard pattern into an anchored regular expression.
+//
+// The pattern supports:
+//   - * for any run of characters, including none
+//   - ? for a single character
+//   - [abc], [a-z] for one of the listed characters, and [!abc] or [^abc] for any other character
+//   - \ to take the next character literally, e.g. \* or \[
+//
+// Every other character matches itself.
+func globToRegexp(pattern string) (string, error) {
+	var b strings.Builder
+	b.WriteString(`(?s)^`)
+
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; r {
+		case '*':
+			b.WriteString(`.*`)
+		case '?':
+			b.WriteString(`.`)
+		case '[':
+			end, err := writeGlobClass(&b, runes, i)
+			if err != nil {
+				return "", err
+			}
+			i = end
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+			}
+			b.WriteString(regexp.QuoteMeta(string(runes[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	b.WriteString(`$`)
+	return b.String(), nil
+}
+
+// writeGlobClass writes the character class that starts at runes[start] and returns the index of its closing ].
+// A ] right after the opening [ (or [! and [^) is part of the class, as in the shell.
+func writeGlobClass(b *strings.Builder, runes []rune, start int) (int, error) {
+	i := start + 1
+	b.WriteByte('[')
+	if i < len(runes) && (runes[i] == '!' || runes[i] == '^') {
+		b.WriteByte('^')
+		i++
+	}
+
+	for first := true; i < len(runes); i, first = i+1, false {
+		r := runes[i]
+		switch {
+		case r == ']' && !first:
+			b.WriteByte(']')
+			return i, nil
+		case r == '\\' && i+1 < len(runes):
+			i++
+			writeClassRune(b, runes[i])
+		case r == '-' && !first && i+1 < len(runes) && runes[i+1] != ']':
+			b.WriteByte('-')
+		default:
+			writeClassRune(b, r)
+		}
+	}
+	return 0, errors.New("unterminated character class")
+}
+
+// writeClassRune writes r so that it stands for itself inside a character class of a regular expression.
+func writeClassRune(b *strings.Builder, r rune) {
+	if r < 0x80 && !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9') {
+		b.WriteByte('\\')
+	}
+	b.WriteRune(r)
+}
+
+// likeToRegexp translates an SQL LIKE pattern into an anchored regular expression.
+// % matches any run of characters, including none, _ matches a single character
+// and \ takes the next character literally, e.g. \% or \_.
+func likeToRegexp(pattern string) string {
+	var b strings.Builder
+	b.WriteString(`(?s)^`)
+
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; r {
+		case '%':
+			b.WriteString(`.*`)
+		case '_':
+			b.WriteString(`.`)
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+			}
+			b.WriteString(regexp.QuoteMeta(string(runes[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	b.WriteString(`$`)
+	return b.String()
+}
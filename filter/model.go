@@ -1,17 +1,38 @@
 package filter
 
-import "time"
+import (
+	"net/netip"
+	"time"
+)
 
 type Operator string
 
 const (
 	OperatorContain            Operator = "CONTAIN"
+	OperatorNotContain         Operator = "NOT_CONTAIN"
+	OperatorStartsWith         Operator = "STARTS_WITH"
+	OperatorEndsWith           Operator = "ENDS_WITH"
 	OperatorEqual              Operator = "EQUAL"
 	OperatorNotEqual           Operator = "NOT_EQUAL"
 	OperatorLessThan           Operator = "LESS_THAN"
 	OperatorGreaterThan        Operator = "GREATER_THAN"
 	OperatorLessThanOrEqual    Operator = "LESS_THAN_OR_EQUAL"
 	OperatorGreaterThanOrEqual Operator = "GREATER_THAN_OR_EQUAL"
+	OperatorMatch              Operator = "MATCH"
+	OperatorNotMatch           Operator = "NOT_MATCH"
+	OperatorGlob               Operator = "GLOB"
+	OperatorLike               Operator = "LIKE"
+	OperatorFuzzy              Operator = "FUZZY"
+	OperatorIn                 Operator = "IN"
+	OperatorNotIn              Operator = "NOT_IN"
+	OperatorInCIDR             Operator = "IN_CIDR"
+	OperatorNotInCIDR          Operator = "NOT_IN_CIDR"
+	OperatorBetween            Operator = "BETWEEN"
+	OperatorCaret              Operator = "CARET"
+	OperatorTilde              Operator = "TILDE"
+	OperatorExists             Operator = "EXISTS"
+	OperatorNotExists          Operator = "NOT_EXISTS"
+	OperatorIsEmpty            Operator = "IS_EMPTY"
 )
 
 type Condition string
@@ -21,18 +42,37 @@ const (
 	ConditionOr  Condition = "OR"
 )
 
+// Bounds selects which ends of a Between range belong to it. Flags can be combined,
+// e.g. BoundsExcludeUpper makes the half-open range lower <= data < upper.
+type Bounds uint8
+
+const (
+	// BoundsInclusive keeps both ends in the range, which is the default.
+	BoundsInclusive    Bounds = 0
+	BoundsExcludeLower Bounds = 1 << 0
+	BoundsExcludeUpper Bounds = 1 << 1
+	BoundsExclusive           = BoundsExcludeLower | BoundsExcludeUpper
+)
+
 type ValueType string
 
 const (
 	ValueTypeNumber   ValueType = "NUMBER"
 	ValueTypeString   ValueType = "STRING"
 	ValueTypeDatetime ValueType = "DATETIME"
+	ValueTypeBool     ValueType = "BOOL"
+	ValueTypeDuration ValueType = "DURATION"
+	ValueTypeIP       ValueType = "IP"
+	ValueTypeSemver   ValueType = "SEMVER"
+	ValueTypeDecimal  ValueType = "DECIMAL"
 )
 
+// ValueNumber lists the Go types of ValueTypeNumber.
+// Integers are compared exactly, without going through float64, so int64 and uint64 keep every bit.
 type ValueNumber interface {
-	int | float64 | float32
+	int | int64 | uint64 | float64 | float32
 }
 
 type Value interface {
-	ValueNumber | string | time.Time
+	ValueNumber | string | time.Time | bool | time.Duration | netip.Addr | Semver | Decimal
 }
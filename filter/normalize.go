@@ -0,0 +1,98 @@
+package filter
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MatchMode controls how string filters compare text. Modes can be combined, e.g. MatchIgnoreCase | MatchIgnoreAccents.
+type MatchMode uint8
+
+const (
+	// MatchCaseSensitive compares the raw bytes of the text, which is the default.
+	MatchCaseSensitive MatchMode = 0
+	// MatchIgnoreCase compares text after Unicode simple case folding, so "Error" matches "ERROR".
+	MatchIgnoreCase MatchMode = 1 << 0
+	// MatchIgnoreAccents compares text with combining marks removed and precomposed Latin letters
+	// reduced to their base letter, so "café", "cafe\u0301" and "cafe" all match each other.
+	MatchIgnoreAccents MatchMode = 1 << 1
+
+	matchModeAll = MatchIgnoreCase | MatchIgnoreAccents
+)
+
+// normalizeString rewrites s so that strings considered equal under mode become byte-equal.
+func normalizeString(s string, mode MatchMode) string {
+	if mode&MatchIgnoreAccents != 0 {
+		s = removeAccents(s)
+	}
+	if mode&MatchIgnoreCase != 0 {
+		s = strings.Map(foldRune, s)
+	}
+	return s
+}
+
+// foldRune maps r to the smallest rune of its simple case folding orbit,
+// so every case variant of a letter (including e.g. the Kelvin sign for K) maps to the same rune.
+func foldRune(r rune) rune {
+	folded := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f < folded {
+			folded = f
+		}
+	}
+	return folded
+}
+
+// removeAccents drops combining marks (decomposed accents) and reduces precomposed Latin letters to their base letter.
+func removeAccents(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.Mn, r) {
+			return -1
+		}
+		return accentBase(r)
+	}, s)
+}
+
+// accentRanges lists, for ranges of precomposed Latin letters, the base letter of every rune
+// in the range starting at first. A '.' marks a rune without a base letter, which is kept as is.
+var accentRanges = []struct {
+	first rune
+	bases string
+}{
+	{
+		// Latin-1 Supplement, Latin Extended-A and -B
+		first: 0x00C0,
+		bases: "" +
+			"AAAAAA.CEEEEIIII.NOOOOO.OUUUUY..aaaaaa.ceeeeiiii.nooooo.ouuuuy.y" +
+			"AaAaAaCcCcCcCcDdDdEeEeEeEeEeGgGgGgGgHhHhIiIiIiIiIi..JjKk.LlLlLlL" +
+			"lLlNnNnNn...OoOoOo..RrRrRrSsSsSsSsTtTtTtUuUuUuUuUuUuWwYyYZzZzZz." +
+			"................................Oo.............Uu..............." +
+			".............AaIiOoUuUuUuUuUu.AaAa....GgKkOoOo..j...Gg..NnAa...." +
+			"AaAaEeEeIiIiOoOoRrRrUuUuSsTt..Hh......AaEeOoOoOoOoYy............" +
+			"................",
+	},
+	{
+		// Latin Extended Additional
+		first: 0x1E00,
+		bases: "" +
+			"AaBbBbBbCcDdDdDdDdDdEeEeEeEeEeFfGgHhHhHhHhHhIiIiKkKkKkLlLlLlLlMm" +
+			"MmMmNnNnNnNnOoOoOoOoPpPpRrRrRrRrSsSsSsSsSsTtTtTtTtUuUuUuUuUuVvVv" +
+			"WwWwWwWwWwXxXxYyZzZzZzhtwy......AaAaAaAaAaAaAaAaAaAaAaAaEeEeEeEe" +
+			"EeEeEeEeIiIiOoOoOoOoOoOoOoOoOoOoOoOoUuUuUuUuUuUuUuYyYyYyYy......",
+	},
+}
+
+func accentBase(r rune) rune {
+	if r < 0x80 {
+		return r
+	}
+	for _, ar := range accentRanges {
+		if r >= ar.first && r < ar.first+rune(len(ar.bases)) {
+			if base := ar.bases[r-ar.first]; base != '.' {
+				return rune(base)
+			}
+			return r
+		}
+	}
+	return r
+}
@@ -0,0 +1,209 @@
+package filter
+
+import (
+	"reflect"
+	"slices"
+)
+
+// mergeable is implemented by filter sets that Optimize can combine with each other.
+type mergeable interface {
+	Filterable
+	// mergeKey returns a comparable key that is equal for sets that read the same Key and hold the same type of filters.
+	// It reports false if the set has no usable Key or cannot be evaluated with condition.
+	mergeKey(condition Condition) (any, bool)
+	// merge combines the set with others, which have the same merge key, into a single set evaluated with condition.
+	merge(others []mergeable, condition Condition) Filterable
+	// dedupe returns the set without duplicate filters.
+	dedupe() Filterable
+	// constant reports whether the set always evaluates to the same result, and which one.
+	constant() (result bool, ok bool)
+}
+
+// Optimize returns a tree that evaluates the same as ft but does less work per record.
+// ft itself is left untouched. It
+//   - flattens nested nodes with the same condition, so (a OR (b OR c)) is treated as one OR of a, b and c,
+//   - removes duplicate filters from filter sets,
+//   - merges sibling leaves that read the same Key and Format with the same value type into a single FSet
+//     with the condition of their parent, so the key is read once instead of once per leaf,
+//   - drops branches whose result is constant, such as a leaf without a DataGetter.
+//
+// Leaves whose FSet has no Key, and negated leaves, are never merged.
+// A tree that turns out to be constant is returned as a node without filter set and condition,
+// which evaluates to false, negated if the constant is true.
+func Optimize(ft *FTree) *FTree {
+	if ft == nil {
+		return nil
+	}
+
+	if ft.FilterSet != nil {
+		node := *ft
+		if set, ok := ft.FilterSet.(mergeable); ok {
+			if result, ok := set.constant(); ok {
+				return constantTree(result != ft.Negate)
+			}
+			node.FilterSet = set.dedupe()
+		}
+		return &node
+	}
+
+	if ft.Condition != ConditionAnd && ft.Condition != ConditionOr {
+		return constantTree(ft.Negate)
+	}
+
+	// the whole chain is flattened before its operands are optimized, so that its leaves are merged once
+	// instead of once per level of the chain
+	var operands []*FTree
+	for _, child := range flatten(flatten(nil, ft.Left, ft.Condition), ft.Right, ft.Condition) {
+		child = Optimize(child)
+		if result, ok := isConstant(child); ok {
+			// true short-circuits OR and false short-circuits AND, the other one is a no-op
+			if result == (ft.Condition == ConditionOr) {
+				return constantTree(result != ft.Negate)
+			}
+			continue
+		}
+		// a child of another condition may turn into a chain of this one, such as (a AND b) OR false
+		operands = flatten(operands, child, ft.Condition)
+	}
+
+	operands = mergeLeaves(operands, ft.Condition)
+
+	if len(operands) == 0 {
+		// every operand was a no-op: an empty AND is true and an empty OR is false
+		return constantTree((ft.Condition == ConditionAnd) != ft.Negate)
+	}
+
+	tree := operands[0]
+	for _, operand := range operands[1:] {
+		tree = &FTree{Left: tree, Right: operand, Condition: ft.Condition}
+	}
+	if ft.Negate {
+		node := *tree
+		node.Negate = !node.Negate
+		tree = &node
+	}
+	return tree
+}
+
+// flatten appends the operands of a chain of nodes with the given condition to operands.
+func flatten(operands []*FTree, ft *FTree, condition Condition) []*FTree {
+	if ft.FilterSet != nil || ft.Condition != condition || ft.Negate {
+		return append(operands, ft)
+	}
+	return flatten(flatten(operands, ft.Left, condition), ft.Right, condition)
+}
+
+// mergeLeaves merges every group of mergeable leaves into the first leaf of the group.
+// The leaves are grouped by their merge key in a single pass and every group is merged at once,
+// so the cost grows linearly with the number of leaves.
+func mergeLeaves(operands []*FTree, condition Condition) []*FTree {
+	merged := make([]*FTree, 0, len(operands))
+	groups := map[any]int{}         // merge key of a group to the index of its first leaf in merged
+	others := map[int][]mergeable{} // sets merged into the first leaf at an index of merged
+	for _, operand := range operands {
+		set, ok := operand.FilterSet.(mergeable)
+		if !ok || operand.Negate {
+			merged = append(merged, operand)
+			continue
+		}
+		key, ok := set.mergeKey(condition)
+		if !ok {
+			merged = append(merged, operand)
+			continue
+		}
+
+		if i, ok := groups[key]; ok {
+			others[i] = append(others[i], set)
+			continue
+		}
+		groups[key] = len(merged)
+		merged = append(merged, operand)
+	}
+
+	for i, sets := range others {
+		merged[i] = &FTree{FilterSet: merged[i].FilterSet.(mergeable).merge(sets, condition)}
+	}
+	return merged
+}
+
+// isConstant reports whether ft always evaluates to the same result, and which one.
+func isConstant(ft *FTree) (bool, bool) {
+	if ft.FilterSet == nil && ft.Condition != ConditionAnd && ft.Condition != ConditionOr {
+		return ft.Negate, true
+	}
+	if set, ok := ft.FilterSet.(mergeable); ok {
+		if result, ok := set.constant(); ok {
+			return result != ft.Negate, true
+		}
+	}
+	return false, false
+}
+
+// constantTree returns a node that always evaluates to result.
+func constantTree(result bool) *FTree {
+	return &FTree{Negate: result}
+}
+
+// setKey is the merge key of an FSet[T], so sets of different value types never share one.
+type setKey[T Value] struct {
+	key    any
+	format any
+}
+
+func (f FSet[T]) mergeKey(condition Condition) (any, bool) {
+	// a key that is unknown or cannot be compared says nothing about what the DataGetter reads
+	if f.Key == nil || !reflect.TypeOf(f.Key).Comparable() || (f.Format != nil && !reflect.TypeOf(f.Format).Comparable()) {
+		return nil, false
+	}
+	// a set without filters tests that the value is present, which no merged set keeps
+	if len(f.Filters) == 0 || (len(f.Filters) > 1 && f.Condition != condition) {
+		return nil, false
+	}
+	return setKey[T]{key: f.Key, format: f.Format}, true
+}
+
+func (f FSet[T]) merge(others []mergeable, condition Condition) Filterable {
+	filters := slices.Clone(f.Filters)
+	for _, other := range others {
+		filters = append(filters, other.(FSet[T]).Filters...)
+	}
+
+	set := NewFilterSet(f.DataGetter, filters, condition)
+	set.Key = f.Key
+	set.Format = f.Format
+	return set.dedupe()
+}
+
+func (f FSet[T]) dedupe() Filterable {
+	if len(f.Filters) < 2 {
+		return f
+	}
+
+	filters := make([]Filter[T], 0, len(f.Filters))
+	// indexes into filters of the kept filters by their key, which only lists of values can tell apart
+	kept := make(map[filterKey[T]][]int, len(f.Filters))
+	for _, filter := range f.Filters {
+		key := filter.key()
+		if slices.ContainsFunc(kept[key], func(i int) bool { return filters[i].equal(filter) }) {
+			continue
+		}
+		kept[key] = append(kept[key], len(filters))
+		filters = append(filters, filter)
+	}
+	if len(filters) == len(f.Filters) {
+		return f
+	}
+
+	set := NewFilterSet(f.DataGetter, filters, f.Condition)
+	set.Key = f.Key
+	set.Format = f.Format
+	return set
+}
+
+func (f FSet[T]) constant() (bool, bool) {
+	// filt never matches without a DataGetter
+	if f.DataGetter == nil {
+		return false, true
+	}
+	return false, false
+}
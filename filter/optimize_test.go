@@ -0,0 +1,228 @@
+package filter
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// keyedLeaf returns a leaf with a single filter reading key from data.
+func keyedLeaf[T Value](data map[any]T, key any, operator Operator, valueType ValueType, value T) *FTree {
+	set := NewFilterSet(func() (T, bool) {
+		v, ok := data[key]
+		return v, ok
+	}, []Filter[T]{mustNewFilter(operator, valueType, value)}, ConditionAnd)
+	set.Key = key
+	return &FTree{FilterSet: set}
+}
+
+func TestOptimize(t *testing.T) {
+	strings := map[any]string{"fruit": "banana", "color": "yellow"}
+	numbers := map[any]int{"fruit": 3, "count": 10}
+
+	fruitA := keyedLeaf(strings, "fruit", OperatorContain, ValueTypeString, "a")
+	fruitB := keyedLeaf(strings, "fruit", OperatorContain, ValueTypeString, "b")
+	fruitC := keyedLeaf(strings, "fruit", OperatorContain, ValueTypeString, "c")
+	color := keyedLeaf(strings, "color", OperatorEqual, ValueTypeString, "yellow")
+	count := keyedLeaf(numbers, "count", OperatorGreaterThan, ValueTypeNumber, 5)
+	fruitNumber := keyedLeaf(numbers, "fruit", OperatorEqual, ValueTypeNumber, 3)
+	unkeyed := &FTree{FilterSet: NewFilterSet(func() (string, bool) { return "banana", true },
+		[]Filter[string]{mustNewFilter(OperatorContain, ValueTypeString, "a")}, ConditionAnd)}
+	fruitFormatted := keyedLeaf(strings, "fruit", OperatorContain, ValueTypeString, "b")
+	formatted := fruitFormatted.FilterSet.(FSet[string])
+	formatted.Format = "2006-01-02"
+	fruitFormatted.FilterSet = formatted
+	noGetter := &FTree{FilterSet: FSet[string]{Filters: []Filter[string]{mustNewFilter(OperatorContain, ValueTypeString, "a")}}}
+
+	or := func(left, right *FTree) *FTree { return &FTree{Left: left, Right: right, Condition: ConditionOr} }
+	and := func(left, right *FTree) *FTree { return &FTree{Left: left, Right: right, Condition: ConditionAnd} }
+	negate := func(ft *FTree) *FTree {
+		node := *ft
+		node.Negate = !node.Negate
+		return &node
+	}
+
+	tests := []struct {
+		name string
+		tree *FTree
+		// leaves is the number of leaves expected after optimization
+		leaves int
+		// filters is the number of filters expected in the first leaf after optimization
+		filters int
+		// constant is set if the optimized tree is expected to be a constant
+		constant *bool
+	}{
+		{
+			name:    "Same key leaves of an OR chain are merged",
+			tree:    or(or(fruitA, fruitB), fruitC),
+			leaves:  1,
+			filters: 3,
+		},
+		{
+			name:    "Nested chains are flattened before merging",
+			tree:    or(fruitA, or(color, or(fruitB, fruitC))),
+			leaves:  2,
+			filters: 3,
+		},
+		{
+			name:    "Same key leaves of an AND chain are merged",
+			tree:    and(fruitA, and(count, fruitB)),
+			leaves:  2,
+			filters: 2,
+		},
+		{
+			name:    "Duplicate filters are removed",
+			tree:    or(fruitA, or(fruitA, fruitB)),
+			leaves:  1,
+			filters: 2,
+		},
+		{
+			name:    "Different value types on the same key are not merged",
+			tree:    or(fruitA, fruitNumber),
+			leaves:  2,
+			filters: 1,
+		},
+		{
+			name:    "Different formats on the same key are not merged",
+			tree:    or(fruitA, or(fruitFormatted, fruitC)),
+			leaves:  2,
+			filters: 2,
+		},
+		{
+			name:    "Different conditions are not flattened",
+			tree:    and(fruitA, or(fruitB, fruitC)),
+			leaves:  2,
+			filters: 1,
+		},
+		{
+			name:    "Negated leaves are not merged",
+			tree:    or(fruitA, negate(fruitB)),
+			leaves:  2,
+			filters: 1,
+		},
+		{
+			name:    "Negated groups are not flattened",
+			tree:    or(fruitA, negate(or(fruitB, color))),
+			leaves:  3,
+			filters: 1,
+		},
+		{
+			name:    "Leaves without Key are not merged",
+			tree:    or(fruitA, unkeyed),
+			leaves:  2,
+			filters: 1,
+		},
+		{
+			name:    "Constant false operand of OR is dropped",
+			tree:    or(noGetter, or(fruitA, fruitB)),
+			leaves:  1,
+			filters: 2,
+		},
+		{
+			name:     "Constant false operand of AND makes it constant",
+			tree:     and(fruitA, and(noGetter, color)),
+			constant: new(bool),
+		},
+		{
+			name:     "Negated constant false operand of OR makes it constant",
+			tree:     or(fruitA, negate(noGetter)),
+			constant: func() *bool { b := true; return &b }(),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			optimized := Optimize(tc.tree)
+			assert.Equal(t, tc.tree.Evaluate(), optimized.Evaluate())
+
+			if tc.constant != nil {
+				assert.Nil(t, optimized.FilterSet)
+				assert.Equal(t, Condition(""), optimized.Condition)
+				assert.Equal(t, *tc.constant, optimized.Evaluate())
+				return
+			}
+
+			leaves := collectLeaves(optimized)
+			assert.Len(t, leaves, tc.leaves)
+			assert.Len(t, leaves[0].FilterSet.(Describer).Describe().Filters, tc.filters)
+		})
+	}
+}
+
+func TestOptimize_KeepsResults(t *testing.T) {
+	data := map[any]string{"fruit": "banana"}
+	present := NewFilterSet(func() (string, bool) { return data["fruit"], true }, nil, ConditionAnd)
+	present.Key = "fruit"
+	leaves := []*FTree{
+		keyedLeaf(data, "fruit", OperatorContain, ValueTypeString, "nan"),
+		keyedLeaf(data, "fruit", OperatorContain, ValueTypeString, "x"),
+		keyedLeaf(data, "fruit", OperatorEqual, ValueTypeString, "banana"),
+		keyedLeaf(data, "missing", OperatorNotEqual, ValueTypeString, "banana"),
+		// a set without filters matches any present value
+		{FilterSet: present},
+	}
+
+	// every combination of two conditions and optional negation over three leaves
+	for _, outer := range []Condition{ConditionAnd, ConditionOr} {
+		for _, inner := range []Condition{ConditionAnd, ConditionOr} {
+			for mask := 0; mask < 8; mask++ {
+				for _, l := range [][3]int{{0, 1, 2}, {1, 2, 3}, {0, 3, 1}, {2, 0, 0}, {4, 1, 0}, {1, 4, 1}} {
+					tree := &FTree{
+						Left: leaves[l[0]],
+						Right: &FTree{
+							Left:      leaves[l[1]],
+							Right:     leaves[l[2]],
+							Condition: inner,
+							Negate:    mask&1 != 0,
+						},
+						Condition: outer,
+						Negate:    mask&2 != 0,
+					}
+					if mask&4 != 0 {
+						tree.Left = &FTree{FilterSet: tree.Left.FilterSet, Negate: true}
+					}
+					assert.Equal(t, tree.Evaluate(), Optimize(tree).Evaluate(), "outer %s inner %s mask %d leaves %v", outer, inner, mask, l)
+				}
+			}
+		}
+	}
+}
+
+// containChain returns a left-deep OR chain of n Contain leaves on the same key, every value appearing twice.
+func containChain(n int) *FTree {
+	data := map[any]string{"line": "GET /api/v1/items?page=2 200 OK"}
+	tree := keyedLeaf(data, "line", OperatorContain, ValueTypeString, "keyword-0")
+	for i := 1; i < n; i++ {
+		leaf := keyedLeaf(data, "line", OperatorContain, ValueTypeString, fmt.Sprintf("keyword-%d", i/2))
+		tree = &FTree{Left: tree, Right: leaf, Condition: ConditionOr}
+	}
+	return tree
+}
+
+func TestOptimize_LongChain(t *testing.T) {
+	optimized := Optimize(containChain(4000))
+
+	leaves := collectLeaves(optimized)
+	assert.Len(t, leaves, 1)
+	assert.Len(t, leaves[0].FilterSet.(Describer).Describe().Filters, 2000)
+	assert.False(t, optimized.Evaluate())
+}
+
+func BenchmarkOptimize_ContainChain(b *testing.B) {
+	for _, n := range []int{100, 1000, 2000} {
+		tree := containChain(n)
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				Optimize(tree)
+			}
+		})
+	}
+}
+
+func collectLeaves(ft *FTree) []*FTree {
+	if ft.FilterSet != nil {
+		return []*FTree{ft}
+	}
+	return append(collectLeaves(ft.Left), collectLeaves(ft.Right)...)
+}
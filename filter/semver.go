@@ -0,0 +1,151 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Semver is a semantic version such as 2.10.3-beta.1+build.5, see https://semver.org.
+// Versions are ordered by Compare, so 2.10.0 comes after 2.9.0 and 1.0.0-beta before 1.0.0.
+// The pre-release and build metadata are kept as strings so that Semver stays comparable.
+type Semver struct {
+	Major, Minor, Patch uint64
+	Prerelease          string // dot-separated identifiers after "-", e.g. "beta.1"
+	Build               string // dot-separated identifiers after "+", ignored by Compare
+}
+
+// ParseSemver parses a version such as "v2.10.3-beta.1". The "v" prefix is optional,
+// and a missing minor or patch number is 0, so "v2.10" is 2.10.0.
+func ParseSemver(s string) (Semver, error) {
+	text := s
+	if strings.HasPrefix(text, "v") || strings.HasPrefix(text, "V") {
+		text = text[1:]
+	}
+
+	var v Semver
+	var ok bool
+	if text, v.Build, ok = strings.Cut(text, "+"); ok && !validIdentifiers(v.Build) {
+		return Semver{}, fmt.Errorf("invalid build metadata in version %q", s)
+	}
+	if text, v.Prerelease, ok = strings.Cut(text, "-"); ok && !validIdentifiers(v.Prerelease) {
+		return Semver{}, fmt.Errorf("invalid pre-release in version %q", s)
+	}
+
+	parts := strings.Split(text, ".")
+	if len(parts) > 3 {
+		return Semver{}, fmt.Errorf("invalid version %q", s)
+	}
+	numbers := []*uint64{&v.Major, &v.Minor, &v.Patch}
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return Semver{}, fmt.Errorf("invalid version %q", s)
+		}
+		*numbers[i] = n
+	}
+	return v, nil
+}
+
+// validIdentifiers reports whether s is a non-empty list of dot-separated identifiers of [0-9A-Za-z-].
+func validIdentifiers(s string) bool {
+	for _, identifier := range strings.Split(s, ".") {
+		if identifier == "" {
+			return false
+		}
+		for _, c := range identifier {
+			if !('0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// String returns the version in canonical form, without a "v" prefix.
+func (v Semver) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 as v has a lower, the same or a higher precedence than other.
+// A pre-release has a lower precedence than its release, and build metadata is ignored.
+func (v Semver) Compare(other Semver) int {
+	for _, pair := range [][2]uint64{{v.Major, other.Major}, {v.Minor, other.Minor}, {v.Patch, other.Patch}} {
+		if pair[0] != pair[1] {
+			if pair[0] < pair[1] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case v.Prerelease == other.Prerelease:
+		return 0
+	case v.Prerelease == "":
+		return 1
+	case other.Prerelease == "":
+		return -1
+	}
+
+	a, b := strings.Split(v.Prerelease, "."), strings.Split(other.Prerelease, ".")
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := compareIdentifier(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	}
+	return 0
+}
+
+// compareIdentifier compares two pre-release identifiers: numeric ones numerically and below alphanumeric ones,
+// which are compared in ASCII order.
+func compareIdentifier(a, b string) int {
+	na, errA := strconv.ParseUint(a, 10, 64)
+	nb, errB := strconv.ParseUint(b, 10, 64)
+	switch {
+	case errA == nil && errB == nil:
+		if na == nb {
+			return 0
+		}
+		if na < nb {
+			return -1
+		}
+		return 1
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// compatibleUpper returns the exclusive upper end of the Caret or Tilde range starting at v:
+//   - ^1.2.3 allows changes that keep the left-most non-zero number, so it ends before 2.0.0,
+//     ^0.2.3 ends before 0.3.0 and ^0.0.3 before 0.0.4,
+//   - ~1.2.3 allows patch changes, so it ends before 1.3.0.
+//
+// The end is the lowest pre-release of that version, so that pre-releases of 2.0.0 are not in ^1.2.3 either.
+func compatibleUpper(operator Operator, v Semver) Semver {
+	switch {
+	case operator == OperatorTilde:
+		return Semver{Major: v.Major, Minor: v.Minor + 1, Prerelease: "0"}
+	case v.Major > 0:
+		return Semver{Major: v.Major + 1, Prerelease: "0"}
+	case v.Minor > 0:
+		return Semver{Minor: v.Minor + 1, Prerelease: "0"}
+	}
+	return Semver{Patch: v.Patch + 1, Prerelease: "0"}
+}
@@ -0,0 +1,105 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mustParseSemver(s string) Semver {
+	v, err := ParseSemver(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func TestParseSemver(t *testing.T) {
+	tests := map[string]Semver{
+		"1.2.3":               {Major: 1, Minor: 2, Patch: 3},
+		"v2.10.3-beta.1":      {Major: 2, Minor: 10, Patch: 3, Prerelease: "beta.1"},
+		"V1.0.0-rc-1+build.5": {Major: 1, Prerelease: "rc-1", Build: "build.5"},
+		"v2.10":               {Major: 2, Minor: 10},
+		"3":                   {Major: 3},
+		"1.0.0+20250320":      {Major: 1, Build: "20250320"},
+	}
+	for text, want := range tests {
+		t.Run(text, func(t *testing.T) {
+			got, err := ParseSemver(text)
+			assert.NoError(t, err)
+			assert.Equal(t, want, got)
+		})
+	}
+
+	for _, text := range []string{"", "v", "1.2.3.4", "1.x.3", "1.2.3-", "1.2.3-beta..1", "1.2.3+", "1.2.3-b_1", "-1.2.3"} {
+		_, err := ParseSemver(text)
+		assert.Error(t, err, text)
+	}
+}
+
+func TestSemver_String(t *testing.T) {
+	assert.Equal(t, "2.10.3-beta.1+build.5", mustParseSemver("v2.10.3-beta.1+build.5").String())
+	assert.Equal(t, "2.10.0", mustParseSemver("v2.10").String())
+}
+
+func TestSemver_Compare(t *testing.T) {
+	// in increasing precedence, as listed by the semver specification
+	ordered := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2", "1.0.0-beta.11",
+		"1.0.0-rc.1", "1.0.0", "1.0.1", "1.9.0", "1.10.0", "2.0.0-0", "2.0.0",
+	}
+	for i, a := range ordered {
+		for j, b := range ordered {
+			want := 0
+			if i < j {
+				want = -1
+			} else if i > j {
+				want = 1
+			}
+			assert.Equal(t, want, mustParseSemver(a).Compare(mustParseSemver(b)), "%s vs %s", a, b)
+		}
+	}
+
+	assert.Equal(t, 0, mustParseSemver("1.0.0+a").Compare(mustParseSemver("1.0.0+b")))
+}
+
+func TestFilter_Semver(t *testing.T) {
+	tests := []struct {
+		name     string
+		operator Operator
+		value    string
+		data     string
+		want     bool
+	}{
+		{name: "Greater than by number, not text", operator: OperatorGreaterThan, value: "2.9.0", data: "2.10.3", want: true},
+		{name: "Pre-release below its release", operator: OperatorLessThan, value: "2.10.3", data: "2.10.3-beta.1", want: true},
+		{name: "Equal ignores build metadata", operator: OperatorEqual, value: "1.0.0+a", data: "1.0.0+b", want: true},
+		{name: "Not equal", operator: OperatorNotEqual, value: "1.0.0", data: "1.0.0-rc.1", want: true},
+		{name: "Caret keeps the major version", operator: OperatorCaret, value: "1.2.3", data: "1.9.0", want: true},
+		{name: "Caret excludes the next major version", operator: OperatorCaret, value: "1.2.3", data: "2.0.0", want: false},
+		{name: "Caret excludes pre-releases of the next major version", operator: OperatorCaret, value: "1.2.3", data: "2.0.0-beta", want: false},
+		{name: "Caret excludes lower versions", operator: OperatorCaret, value: "1.2.3", data: "1.2.3-beta", want: false},
+		{name: "Caret on a zero major version keeps the minor version", operator: OperatorCaret, value: "0.2.3", data: "0.3.0", want: false},
+		{name: "Caret on 0.0.x keeps the patch version", operator: OperatorCaret, value: "0.0.3", data: "0.0.4", want: false},
+		{name: "Tilde keeps the minor version", operator: OperatorTilde, value: "1.2.3", data: "1.2.9", want: true},
+		{name: "Tilde excludes the next minor version", operator: OperatorTilde, value: "1.2.3", data: "1.3.0", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := mustNewFilter(tt.operator, ValueTypeSemver, mustParseSemver(tt.value))
+			assert.Equal(t, tt.want, f.filtData(mustParseSemver(tt.data)))
+		})
+	}
+
+	between, err := NewRangeFilter(ValueTypeSemver, mustParseSemver("1.0.0"), mustParseSemver("1.10.0"), BoundsInclusive)
+	assert.NoError(t, err)
+	assert.True(t, between.filtData(mustParseSemver("1.9.9")))
+
+	_, err = NewRangeFilter(ValueTypeSemver, mustParseSemver("1.10.0"), mustParseSemver("1.9.0"), BoundsInclusive)
+	assert.ErrorIs(t, err, ErrInvalidRange)
+	_, err = NewFilter(OperatorCaret, ValueTypeNumber, 1)
+	assert.ErrorIs(t, err, ErrInvalidOperator)
+	_, err = NewFilter(OperatorContain, ValueTypeSemver, mustParseSemver("1.0.0"))
+	assert.ErrorIs(t, err, ErrInvalidOperator)
+}
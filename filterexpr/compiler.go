@@ -0,0 +1,412 @@
+package filterexpr
+
+import (
+	"errors"
+	"fejsal/filter"
+	"fejsal/reader"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Options configures how an Expr is compiled into a filter.FTree.
+type Options struct {
+	// TimeLayout is the layout used to parse datetime filter values and the data read for them.
+	// It defaults to time.DateTime. Values that do not match it are also tried with timeFallbackLayouts.
+	TimeLayout string
+	// DurationUnit is the unit of bare numbers in duration filter values and the data read for them,
+	// e.g. time.Millisecond to read "250" as 250ms. Without it only Go durations such as "250ms" are accepted.
+	DurationUnit time.Duration
+	// Optimize runs filter.Optimize on the compiled tree, merging filters on the same key into one FSet.
+	Optimize bool
+}
+
+func (o Options) timeLayout() string {
+	if o.TimeLayout == "" {
+		return time.DateTime
+	}
+	return o.TimeLayout
+}
+
+// timeFallbackLayouts are tried in order for a datetime value that does not match the time layout,
+// so a day can be written as 2025-03-20 whatever layout the data uses.
+var timeFallbackLayouts = []string{time.RFC3339Nano, time.DateTime, time.DateOnly}
+
+// Compile turns an Expr into an executable filter.FTree.
+// Every filter node becomes a leaf holding an FSet whose DataGetter reads the filter's key from r,
+// every operator node becomes an AND/OR node of the tree and a NOT node negates the tree of its operand.
+//
+// Type and operator mismatches such as (number,0,contain,3) are reported through filter.Filter.Validate,
+// and keys the reader cannot address through reader.StreamReader.ValidateKey.
+func Compile(expr *Expr, r reader.StreamReader, opts Options) (*filter.FTree, error) {
+	tree, err := compile(expr, r, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Optimize {
+		tree = filter.Optimize(tree)
+	}
+	return tree, nil
+}
+
+func compile(expr *Expr, r reader.StreamReader, opts Options) (*filter.FTree, error) {
+	if expr == nil {
+		return nil, errors.New("filterexpr: nil expression")
+	}
+
+	switch expr.Type {
+	case NodeFilter:
+		tree, err := compileFilter(expr.Filter, r, opts)
+		if err != nil {
+			return nil, fmt.Errorf("filterexpr: filter %s: %w", expr.Filter, err)
+		}
+		return tree, nil
+	case NodeOp:
+		var condition filter.Condition
+		switch normalizeOp(expr.Op) {
+		case OpAnd:
+			condition = filter.ConditionAnd
+		case OpOr:
+			condition = filter.ConditionOr
+		default:
+			return nil, fmt.Errorf("filterexpr: unknown logical operator %q", expr.Op)
+		}
+
+		left, err := compile(expr.Left, r, opts)
+		if err != nil {
+			return nil, err
+		}
+		right, err := compile(expr.Right, r, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &filter.FTree{Left: left, Right: right, Condition: condition}, nil
+	case NodeNot:
+		operand, err := compile(expr.Left, r, opts)
+		if err != nil {
+			return nil, err
+		}
+		operand.Negate = !operand.Negate
+		return operand, nil
+	}
+
+	return nil, fmt.Errorf("filterexpr: unknown node type %d", expr.Type)
+}
+
+// compileFilter builds a typed filter from raw and wraps it into a single-filter leaf.
+func compileFilter(raw RawFilter, r reader.StreamReader, opts Options) (*filter.FTree, error) {
+	valueType, err := LookupValueType(raw.ValueType)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := parseOperator(raw.Operator)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.ValidateKey(raw.Index); err != nil {
+		return nil, err
+	}
+
+	layout := raw.Layout
+	if layout == "" {
+		layout = opts.timeLayout()
+	}
+	format := getterFormat(valueType, layout, opts.DurationUnit)
+	if isPresenceOperator(spec.operator) {
+		if raw.Value != "" || raw.Values != nil {
+			return nil, fmt.Errorf("operator %s takes no value", raw.Operator)
+		}
+		return compilePresence(raw, r, spec, valueType, layout, opts.DurationUnit)
+	}
+
+	texts := raw.Values
+	bounds := filter.BoundsInclusive
+	switch {
+	case texts != nil:
+	case spec.operator == filter.OperatorBetween:
+		var lower, upper string
+		if lower, upper, bounds, err = splitRange(raw.Value); err != nil {
+			return nil, err
+		}
+		texts = []string{lower, upper}
+	default:
+		texts = []string{raw.Value}
+	}
+
+	switch valueType {
+	case filter.ValueTypeString:
+		return newLeaf(r.StringGetter(raw.Index), raw, spec, valueType, texts, bounds, format)
+	case filter.ValueTypeNumber:
+		return compileNumber(raw, r, spec, valueType, texts, bounds)
+	case filter.ValueTypeDatetime:
+		times := make([]time.Time, len(texts))
+		for i, text := range texts {
+			t, err := parseTime(text, layout)
+			if err != nil {
+				return nil, err
+			}
+			times[i] = t
+		}
+		return newLeaf(r.TimeGetter(raw.Index, layout), raw, spec, valueType, times, bounds, format)
+	case filter.ValueTypeBool:
+		bools := make([]bool, len(texts))
+		for i, text := range texts {
+			b, ok := reader.DefaultBoolSpellings.Parse(text)
+			if !ok {
+				return nil, fmt.Errorf("invalid bool %q", text)
+			}
+			bools[i] = b
+		}
+		return newLeaf(r.BoolGetter(raw.Index), raw, spec, valueType, bools, bounds, format)
+	case filter.ValueTypeDuration:
+		durations := make([]time.Duration, len(texts))
+		for i, text := range texts {
+			d, err := reader.ParseDuration(text, opts.DurationUnit)
+			if err != nil {
+				return nil, err
+			}
+			durations[i] = d
+		}
+		return newLeaf(r.DurationGetter(raw.Index, opts.DurationUnit), raw, spec, valueType, durations, bounds, format)
+	case filter.ValueTypeSemver:
+		versions := make([]filter.Semver, len(texts))
+		for i, text := range texts {
+			v, err := filter.ParseSemver(text)
+			if err != nil {
+				return nil, err
+			}
+			versions[i] = v
+		}
+		return newLeaf(r.SemverGetter(raw.Index), raw, spec, valueType, versions, bounds, format)
+	case filter.ValueTypeDecimal:
+		decimals := make([]filter.Decimal, len(texts))
+		for i, text := range texts {
+			d, err := filter.ParseDecimal(text)
+			if err != nil {
+				return nil, err
+			}
+			decimals[i] = d
+		}
+		return newLeaf(r.DecimalGetter(raw.Index), raw, spec, valueType, decimals, bounds, format)
+	case filter.ValueTypeIP:
+		if spec.operator == filter.OperatorInCIDR || spec.operator == filter.OperatorNotInCIDR {
+			return compileCIDR(r.IPGetter(raw.Index), raw, spec, valueType, texts)
+		}
+		addrs := make([]netip.Addr, len(texts))
+		for i, text := range texts {
+			addr, err := netip.ParseAddr(text)
+			if err != nil {
+				return nil, fmt.Errorf("invalid IP address %q", text)
+			}
+			addrs[i] = addr
+		}
+		return newLeaf(r.IPGetter(raw.Index), raw, spec, valueType, addrs, bounds, format)
+	}
+
+	return nil, fmt.Errorf("unsupported value type %q", valueType)
+}
+
+// compilePresence builds an Exists, NotExists or IsEmpty filter, which has no value to parse.
+// Numbers are read as floats, so any number is present and not only integers.
+func compilePresence(raw RawFilter, r reader.StreamReader, spec operatorSpec, valueType filter.ValueType, layout string, unit time.Duration) (*filter.FTree, error) {
+	format := getterFormat(valueType, layout, unit)
+	switch valueType {
+	case filter.ValueTypeString:
+		return newPresenceLeaf(r.StringGetter(raw.Index), raw, spec, valueType, format)
+	case filter.ValueTypeNumber:
+		return newPresenceLeaf(r.FloatGetter(raw.Index), raw, spec, valueType, format)
+	case filter.ValueTypeDatetime:
+		return newPresenceLeaf(r.TimeGetter(raw.Index, layout), raw, spec, valueType, format)
+	case filter.ValueTypeBool:
+		return newPresenceLeaf(r.BoolGetter(raw.Index), raw, spec, valueType, format)
+	case filter.ValueTypeDuration:
+		return newPresenceLeaf(r.DurationGetter(raw.Index, unit), raw, spec, valueType, format)
+	case filter.ValueTypeIP:
+		return newPresenceLeaf(r.IPGetter(raw.Index), raw, spec, valueType, format)
+	case filter.ValueTypeSemver:
+		return newPresenceLeaf(r.SemverGetter(raw.Index), raw, spec, valueType, format)
+	case filter.ValueTypeDecimal:
+		return newPresenceLeaf(r.DecimalGetter(raw.Index), raw, spec, valueType, format)
+	}
+	return nil, fmt.Errorf("unsupported value type %q", valueType)
+}
+
+// getterFormat returns what decides, besides the key, how the getter of a filter of valueType parses its field,
+// so that filter.Optimize only merges leaves that read the same value, see filter.FSet.Format.
+func getterFormat(valueType filter.ValueType, layout string, unit time.Duration) any {
+	switch valueType {
+	case filter.ValueTypeDatetime:
+		return layout
+	case filter.ValueTypeDuration:
+		return unit
+	}
+	return nil
+}
+
+// compileCIDR builds an InCIDR or NotInCIDR filter from prefixes such as 10.0.0.0/8,
+// where a bare address such as 10.0.0.1 stands for itself alone.
+func compileCIDR(getter func() (netip.Addr, bool), raw RawFilter, spec operatorSpec, valueType filter.ValueType, texts []string) (*filter.FTree, error) {
+	prefixes := make([]netip.Prefix, len(texts))
+	for i, text := range texts {
+		prefix, err := netip.ParsePrefix(text)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(text)
+			if addrErr != nil {
+				return nil, fmt.Errorf("invalid prefix %q", text)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		prefixes[i] = prefix
+	}
+
+	f, err := filter.NewCIDRFilter(spec.operator, valueType, prefixes)
+	if err != nil {
+		return nil, err
+	}
+	return wrapLeaf(getter, raw, spec, f, nil)
+}
+
+// rangeSeparator separates the ends of a Between value.
+const rangeSeparator = ".."
+
+// splitRange splits the value of a Between filter written as lower..upper into its ends.
+// A "<" next to the dots excludes the end on that side: lower..<upper excludes upper,
+// lower<..upper excludes lower and lower<..<upper excludes both.
+func splitRange(value string) (string, string, filter.Bounds, error) {
+	lower, upper, ok := strings.Cut(value, rangeSeparator)
+	if !ok {
+		return "", "", 0, fmt.Errorf("invalid range %q, expected lower..upper", value)
+	}
+
+	bounds := filter.BoundsInclusive
+	if trimmed, ok := strings.CutSuffix(lower, "<"); ok {
+		lower = trimmed
+		bounds |= filter.BoundsExcludeLower
+	}
+	if trimmed, ok := strings.CutPrefix(upper, "<"); ok {
+		upper = trimmed
+		bounds |= filter.BoundsExcludeUpper
+	}
+
+	lower, upper = strings.TrimSpace(lower), strings.TrimSpace(upper)
+	if lower == "" || upper == "" {
+		return "", "", 0, fmt.Errorf("invalid range %q, expected lower..upper", value)
+	}
+	return lower, upper, bounds, nil
+}
+
+// parseTime parses text with layout, falling back to timeFallbackLayouts.
+func parseTime(text, layout string) (time.Time, error) {
+	if t, err := time.Parse(layout, text); err == nil {
+		return t, nil
+	}
+	for _, fallback := range timeFallbackLayouts {
+		if t, err := time.Parse(fallback, text); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid datetime %q for layout %q", text, layout)
+}
+
+// compileNumber builds a number filter. Integers of any size are compared as exact decimals, so that they also
+// match data such as 3.5 or -5, unless the type is spelled int64, uint64 or float, or a value is not an integer,
+// which makes them float64.
+func compileNumber(raw RawFilter, r reader.StreamReader, spec operatorSpec, valueType filter.ValueType, texts []string, bounds filter.Bounds) (*filter.FTree, error) {
+	parseFloat := func(text string) (float64, error) { return strconv.ParseFloat(text, 64) }
+	parseInt64 := func(text string) (int64, error) { return strconv.ParseInt(text, 10, 64) }
+	parseUint64 := func(text string) (uint64, error) { return strconv.ParseUint(text, 10, 64) }
+	parseInteger := func(text string) (filter.Decimal, error) {
+		if strings.ContainsAny(text, ".eE") {
+			return filter.Decimal{}, strconv.ErrSyntax
+		}
+		return filter.ParseDecimal(text)
+	}
+
+	switch strings.ToLower(raw.ValueType) {
+	case "float":
+	case "int64":
+		ints, err := parseNumbers(texts, parseInt64)
+		if err != nil {
+			return nil, err
+		}
+		return newLeaf(r.Int64Getter(raw.Index), raw, spec, valueType, ints, bounds, nil)
+	case "uint64":
+		uints, err := parseNumbers(texts, parseUint64)
+		if err != nil {
+			return nil, err
+		}
+		return newLeaf(r.Uint64Getter(raw.Index), raw, spec, valueType, uints, bounds, nil)
+	default:
+		if ints, err := parseNumbers(texts, parseInteger); err == nil {
+			return newLeaf(r.DecimalGetter(raw.Index), raw, spec, valueType, ints, bounds, nil)
+		}
+	}
+
+	floats, err := parseNumbers(texts, parseFloat)
+	if err != nil {
+		return nil, err
+	}
+	return newLeaf(r.FloatGetter(raw.Index), raw, spec, valueType, floats, bounds, nil)
+}
+
+// parseNumbers parses every text with parse and fails on the first one that is not a valid number.
+func parseNumbers[N filter.Value](texts []string, parse func(string) (N, error)) ([]N, error) {
+	numbers := make([]N, len(texts))
+	for i, text := range texts {
+		n, err := parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", text)
+		}
+		numbers[i] = n
+	}
+	return numbers, nil
+}
+
+// newLeaf wraps a single filter into a leaf whose set reads the key of raw with getter.
+// values holds the parsed list of raw, which becomes an In/NotIn set filter, the lower and upper end
+// of a Between filter with the given bounds, or else the single value of raw, which a Fuzzy filter
+// compares within the distance of spec.
+func newLeaf[T filter.Value](getter func() (T, bool), raw RawFilter, spec operatorSpec, valueType filter.ValueType, values []T, bounds filter.Bounds, format any) (*filter.FTree, error) {
+	var f filter.Filter[T]
+	var err error
+	switch {
+	case raw.Values != nil:
+		f, err = filter.NewSetFilter(spec.operator, valueType, values)
+	case spec.operator == filter.OperatorBetween:
+		f, err = filter.NewRangeFilter(valueType, values[0], values[1], bounds)
+	case spec.operator == filter.OperatorFuzzy:
+		f, err = filter.NewFuzzyFilter(valueType, values[0], spec.distance, spec.fuzzyMode)
+	default:
+		f, err = filter.NewFilter(spec.operator, valueType, values[0])
+	}
+	if err != nil {
+		return nil, err
+	}
+	return wrapLeaf(getter, raw, spec, f, format)
+}
+
+// newPresenceLeaf wraps a presence filter into a leaf whose set reads the key of raw with getter.
+func newPresenceLeaf[T filter.Value](getter func() (T, bool), raw RawFilter, spec operatorSpec, valueType filter.ValueType, format any) (*filter.FTree, error) {
+	f, err := filter.NewPresenceFilter[T](spec.operator, valueType)
+	if err != nil {
+		return nil, err
+	}
+	return wrapLeaf(getter, raw, spec, f, format)
+}
+
+// wrapLeaf applies the match mode of spec to f and wraps it into a leaf whose set reads the key of raw with getter,
+// which parses the field according to format (see filter.FSet.Format).
+func wrapLeaf[T filter.Value](getter func() (T, bool), raw RawFilter, spec operatorSpec, f filter.Filter[T], format any) (*filter.FTree, error) {
+	if spec.matchMode != filter.MatchCaseSensitive {
+		var err error
+		if f, err = f.WithMatchMode(spec.matchMode); err != nil {
+			return nil, err
+		}
+	}
+	set := filter.NewFilterSet(getter, []filter.Filter[T]{f}, filter.ConditionAnd)
+	set.Key = raw.Index
+	set.Format = format
+	return &filter.FTree{FilterSet: set}, nil
+}
@@ -0,0 +1,738 @@
+package filterexpr
+
+import (
+	"errors"
+	"fejsal/filter"
+	"fejsal/reader"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleCSV = `1,monkey,loves,banana,2025-03-19 10:00:00,0.5
+2,dog,eat,banana,2025-03-20 10:00:00,1.5
+3,I,drink,banana smoothie,2025-03-21 10:00:00,2.5
+`
+
+// evaluateLines compiles input against a CSVReader and returns the first column of every matching line of sampleCSV.
+func evaluateLines(t *testing.T, input string, opts Options) []string {
+	t.Helper()
+	return evaluateCSV(t, input, sampleCSV, opts)
+}
+
+// evaluateCSV compiles input against a CSVReader and returns the first column of every matching line of data.
+func evaluateCSV(t *testing.T, input, data string, opts Options) []string {
+	t.Helper()
+
+	expr, err := Parse(input)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	csvReader := reader.NewCSVReader()
+	tree, err := Compile(expr, csvReader, opts)
+	if err != nil {
+		t.Fatalf("unexpected compile error: %v", err)
+	}
+
+	idx := csvReader.StringGetter(0)
+	var matched []string
+	csvReader.InputStream(strings.NewReader(data))
+	for csvReader.LoadNextLine() {
+		if tree.Evaluate() {
+			id, _ := idx()
+			matched = append(matched, id)
+		}
+	}
+	return matched
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "String filter",
+			input: "(string,3,contain,smoothie)",
+			want:  []string{"3"},
+		},
+		{
+			name:  "Number filter",
+			input: "(number,0,less_than,3)",
+			want:  []string{"1", "2"},
+		},
+		{
+			name:  "Float number filter",
+			input: "(number,5,greater_than,1.0)",
+			want:  []string{"2", "3"},
+		},
+		{
+			name:  "Datetime filter",
+			input: "(datetime,4,greater_than_or_equal,2025-03-20 00:00:00)",
+			want:  []string{"2", "3"},
+		},
+		{
+			name:  "Nested conditions",
+			input: "((string,3,not_equal,banana smoothie) or (string,1,contain,o)) and (number,0,less_than,3)",
+			want:  []string{"1", "2"},
+		},
+		{
+			name:  "Symbolic operators and short type names",
+			input: "(time,4,>=,2025-03-20 00:00:00) and (int,0,!=,3) or (str,1,~,mon)",
+			want:  []string{"1", "2"},
+		},
+		{
+			name:  "Float type forces float comparison",
+			input: "(float,5,<,2)",
+			want:  []string{"1", "2"},
+		},
+		{
+			name:  "Regular expression",
+			input: `(string,3,=~,"^banana$") or (string,1,=~,"^[A-Z]$")`,
+			want:  []string{"1", "2", "3"},
+		},
+		{
+			name:  "Prefix and suffix",
+			input: "(string,3,starts_with,banana) and (string,3,suffix,thie) or (string,1,prefix,mon)",
+			want:  []string{"1", "3"},
+		},
+		{
+			name:  "Negated contain",
+			input: "(string,3,not_contain,smoothie) and (string,1,!contain,dog)",
+			want:  []string{"1"},
+		},
+		{
+			name:  "Negated regular expression",
+			input: `(string,2,!~,"^(eat|drink)$")`,
+			want:  []string{"1"},
+		},
+		{
+			name:  "Negated group",
+			input: "not ((string,3,equal,banana) or (string,1,contain,o))",
+			want:  []string{"3"},
+		},
+		{
+			name:  "Negated CONTAIN",
+			input: "(number,0,greater_than,1) and !(string,3,contain,smoothie)",
+			want:  []string{"2"},
+		},
+		{
+			name:  "Case-insensitive operators",
+			input: "(string,1,icontain,MON) or (string,1,equal:i,i) or (string,2,=~:i,^EAT$)",
+			want:  []string{"1", "2", "3"},
+		},
+		{
+			name:  "Accent-insensitive operators",
+			input: "(string,3,contain:a,smóothié) or (string,1,ieq:a,DÖG)",
+			want:  []string{"2", "3"},
+		},
+		{
+			name:  "Glob and LIKE",
+			input: `(string,3,glob,"banana ?moo*") or (string,1,like,m_nk%) or (string,1,iglob,"[a-h]*")`,
+			want:  []string{"1", "2", "3"},
+		},
+		{
+			name:  "Fuzzy",
+			input: "(string,2,fuzzy:1,lovse) or (string,1,ifuzzy,DOGE) or (string,3,fuzzy:1:w,smothie)",
+			want:  []string{"2", "3"},
+		},
+		{
+			name:  "String set",
+			input: "(string,1,in,[dog,I,cat])",
+			want:  []string{"2", "3"},
+		},
+		{
+			name:  "Number set",
+			input: "(int,0,not_in,[1,3]) or (float,5,in,[0.5])",
+			want:  []string{"1", "2"},
+		},
+		{
+			name:  "Set ignoring case",
+			input: "(string,1,iin,[MONKEY,Dog]) and (string,3,!in,[])",
+			want:  []string{"1", "2"},
+		},
+		{
+			name:  "Single value set",
+			input: "(string,2,in,eat)",
+			want:  []string{"2"},
+		},
+		{
+			name:  "Datetime range",
+			input: "(time,4,between,2025-03-20..2025-03-21)",
+			want:  []string{"2"},
+		},
+		{
+			name:  "Number ranges with excluded ends",
+			input: "(int,0,between,1..<3) and (float,5,between,0.5<..2.5)",
+			want:  []string{"2"},
+		},
+		{
+			name:  "Datetime equal",
+			input: "(time,4,==,2025-03-20 10:00:00)",
+			want:  []string{"2"},
+		},
+		{
+			name:  "Datetime not equal",
+			input: "(datetime,4,!=,2025-03-20 10:00:00)",
+			want:  []string{"1", "3"},
+		},
+		{
+			name:  "Datetime without a time of day",
+			input: "(datetime,4,greater_than,2025-03-21)",
+			want:  []string{"3"},
+		},
+		{
+			name:  "OR across types",
+			input: "(string,1,equal,I) or (number,0,equal,1)",
+			want:  []string{"1", "3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := evaluateLines(t, tt.input, Options{})
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("matched lines mismatch\nGot: %v\nWant: %v", got, tt.want)
+			}
+
+			optimized := evaluateLines(t, tt.input, Options{Optimize: true})
+			if strings.Join(optimized, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("optimized matched lines mismatch\nGot: %v\nWant: %v", optimized, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompile_Optimize(t *testing.T) {
+	expr, err := Parse("(string,1,contain,a) or ((string,1,contain,b) or (string,1,contain,c)) or (string,1,contain,a)")
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	tree, err := Compile(expr, reader.NewCSVReader(), Options{Optimize: true})
+	if err != nil {
+		t.Fatalf("unexpected compile error: %v", err)
+	}
+
+	set, ok := tree.FilterSet.(filter.FSet[string])
+	if !ok {
+		t.Fatalf("expected a single FSet[string] leaf, got %#v", tree)
+	}
+	if len(set.Filters) != 3 || set.Condition != filter.ConditionOr {
+		t.Errorf("expected 3 filters under OR, got %d under %s", len(set.Filters), set.Condition)
+	}
+
+	got, err := FormatTree(tree, Options{})
+	if err != nil {
+		t.Fatalf("unexpected format error: %v", err)
+	}
+	if want := "(string,1,contain,a) or (string,1,contain,b) or (string,1,contain,c)"; got != want {
+		t.Errorf("format result mismatch\nGot: %s\nWant: %s", got, want)
+	}
+}
+
+func TestCompile_ManyContains(t *testing.T) {
+	// enough keywords on one key for the optimized set to check them with a single automaton
+	var keywords, negated []string
+	for i := 0; i < 30; i++ {
+		keywords = append(keywords, fmt.Sprintf("(string,3,contain,keyword%d)", i))
+		negated = append(negated, fmt.Sprintf("(string,3,!contain,keyword%d)", i))
+	}
+	data := "1,a,b,no keyword here\n2,a,b,keyword12 here\n3,a,b,KEYWORD7\n4,a,b,keyword29\n"
+
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "OR of contains", input: strings.Join(keywords, " or "), want: []string{"2", "4"}},
+		{name: "AND of not contains", input: strings.Join(negated, " and "), want: []string{"1", "3"}},
+		{name: "OR of contains with another key", input: strings.Join(keywords, " or ") + " or (string,0,eq,1)", want: []string{"1", "2", "4"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, optimize := range []bool{false, true} {
+				got := evaluateCSV(t, tt.input, data, Options{Optimize: optimize})
+				if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+					t.Errorf("matched lines mismatch with Optimize %v\nGot: %v\nWant: %v", optimize, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// blocklist returns an OR of n contain filters on column 3.
+func blocklist(n int) string {
+	keywords := make([]string, n)
+	for i := range keywords {
+		keywords[i] = fmt.Sprintf("(string,3,contain,blocked-%d)", i)
+	}
+	return strings.Join(keywords, " or ")
+}
+
+func TestCompile_Blocklist(t *testing.T) {
+	expr, err := Parse(blocklist(2000))
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	start := time.Now()
+	tree, err := Compile(expr, reader.NewCSVReader(), Options{Optimize: true})
+	if err != nil {
+		t.Fatalf("unexpected compile error: %v", err)
+	}
+	// merging used to take tens of seconds for this many leaves, it now takes milliseconds
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("compiling 2000 keywords took %v", elapsed)
+	}
+
+	// a single set of that many contain filters under OR checks them all with one automaton
+	set, ok := tree.FilterSet.(filter.FSet[string])
+	if !ok {
+		t.Fatalf("expected a single FSet[string] leaf, got %#v", tree)
+	}
+	if len(set.Filters) != 2000 || set.Condition != filter.ConditionOr {
+		t.Errorf("expected 2000 filters under OR, got %d under %s", len(set.Filters), set.Condition)
+	}
+
+	got := evaluateCSV(t, blocklist(2000), "1,a,b,fine\n2,a,b,is blocked-1999 here\n3,a,b,blocked-\n", Options{Optimize: true})
+	if strings.Join(got, ",") != "2" {
+		t.Errorf("matched lines mismatch\nGot: %v\nWant: [2]", got)
+	}
+}
+
+func BenchmarkCompile_Blocklist(b *testing.B) {
+	for _, n := range []int{100, 2000} {
+		expr, err := Parse(blocklist(n))
+		if err != nil {
+			b.Fatalf("unexpected parse error: %v", err)
+		}
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := Compile(expr, reader.NewCSVReader(), Options{Optimize: true}); err != nil {
+					b.Fatalf("unexpected compile error: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestCompile_PatternEscapes(t *testing.T) {
+	const data = `1,req-123456,file*.txt,50%
+2,req-12345a,file1.txt,50 percent
+3,ERR-42,C:\path\x,5%
+`
+
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "Regular expression class", input: `(string,1,=~,"^req-\d{6}$")`, want: []string{"1"}},
+		{name: "Regular expression without quotes", input: `(string,1,=~,^ERR-\d+)`, want: []string{"3"}},
+		{name: "Escaped glob wildcard", input: `(string,2,glob,file\*.txt)`, want: []string{"1"}},
+		{name: "Escaped LIKE wildcard", input: `(string,3,like,"50\%")`, want: []string{"1"}},
+		{name: "Windows path", input: `(string,2,starts_with,C:\path)`, want: []string{"3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := evaluateCSV(t, tt.input, data, Options{})
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("matched lines mismatch\nGot: %v\nWant: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompile_Presence(t *testing.T) {
+	const data = `1,monkey,,banana
+2,dog,eat
+3,,drink,banana smoothie
+x,I
+`
+
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "Missing column", input: "(string,3,missing)", want: []string{"2", "x"}},
+		{name: "Empty column", input: "(string,2,empty)", want: []string{"1"}},
+		{name: "Present and not empty", input: "(string,1,exists) and not (string,1,is_empty)", want: []string{"1", "2", "x"}},
+		{name: "Missing or matching", input: "(string,3,not_exists) or (string,3,contain,smoothie)", want: []string{"2", "3", "x"}},
+		{name: "Number that cannot be read", input: "(number,0,!exists)", want: []string{"x"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := evaluateCSV(t, tt.input, data, Options{})
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("matched lines mismatch\nGot: %v\nWant: %v", got, tt.want)
+			}
+
+			optimized := evaluateCSV(t, tt.input, data, Options{Optimize: true})
+			if strings.Join(optimized, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("optimized matched lines mismatch\nGot: %v\nWant: %v", optimized, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompile_Bool(t *testing.T) {
+	const data = `1,true
+2,no
+3,Y
+4,maybe
+5
+`
+
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "Equal true", input: "(bool,1,eq,true)", want: []string{"1", "3"}},
+		{name: "Equal with another spelling", input: "(boolean,1,equal,off)", want: []string{"2"}},
+		{name: "Not equal", input: "(bool,1,!=,yes)", want: []string{"2"}},
+		{name: "Unreadable or missing", input: "(bool,1,missing)", want: []string{"4", "5"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := evaluateCSV(t, tt.input, data, Options{})
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("matched lines mismatch\nGot: %v\nWant: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompile_Duration(t *testing.T) {
+	const data = `1,250ms
+2,1.5s
+3,2m3s
+4,750
+5,slow
+`
+
+	tests := []struct {
+		name  string
+		input string
+		opts  Options
+		want  []string
+	}{
+		{name: "Greater than", input: "(duration,1,>,500ms)", want: []string{"2", "3"}},
+		{name: "Range", input: "(dur,1,between,200ms..<1.5s)", want: []string{"1"}},
+		{name: "Set", input: "(duration,1,in,[250ms,123s])", want: []string{"1", "3"}},
+		{name: "Bare numbers with a unit", input: "(duration,1,>,500)", opts: Options{DurationUnit: time.Millisecond}, want: []string{"2", "3", "4"}},
+		{name: "Unreadable", input: "(duration,1,missing)", want: []string{"4", "5"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := evaluateCSV(t, tt.input, data, tt.opts)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("matched lines mismatch\nGot: %v\nWant: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompile_IP(t *testing.T) {
+	const data = `1,10.1.1.1
+2,110.1.1.1
+3,192.168.1.20
+4,fd00::1
+5,not an address
+`
+
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		// a string CONTAIN would also match 110.1.1.1
+		{name: "Single prefix", input: "(ip,1,in_cidr,10.0.0.0/8)", want: []string{"1"}},
+		{name: "Several prefixes", input: "(ip,1,cidr,[10.0.0.0/8,192.168.1.0/24,fd00::/8])", want: []string{"1", "3", "4"}},
+		{name: "Not in prefixes", input: "(ip,1,not_in_cidr,[10.0.0.0/8,192.168.1.0/24])", want: []string{"2", "4"}},
+		{name: "Bare address as a prefix", input: "(ip,1,in_cidr,[110.1.1.1,fd00::1])", want: []string{"2", "4"}},
+		{name: "Equal", input: "(ip,1,eq,192.168.1.20) or (ip,1,eq,fd00:0::1)", want: []string{"3", "4"}},
+		{name: "Unreadable", input: "(ip,1,missing)", want: []string{"5"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := evaluateCSV(t, tt.input, data, Options{})
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("matched lines mismatch\nGot: %v\nWant: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompile_Semver(t *testing.T) {
+	const data = `1,v2.9.0
+2,v2.10.3-beta.1
+3,2.10.3
+4,3.0.0-rc.1
+5,latest
+`
+
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		// as strings, 2.10 would sort below 2.9
+		{name: "Greater than", input: "(semver,1,>,2.9.0)", want: []string{"2", "3", "4"}},
+		{name: "Pre-release below release", input: "(semver,1,<,v2.10.3)", want: []string{"1", "2"}},
+		{name: "Caret", input: "(semver,1,^,2.9.0)", want: []string{"1", "2", "3"}},
+		{name: "Tilde", input: "(version,1,tilde,2.10.0)", want: []string{"2", "3"}},
+		{name: "Range", input: "(semver,1,between,2.10.0..3.0.0)", want: []string{"2", "3", "4"}},
+		{name: "Unreadable", input: "(semver,1,missing)", want: []string{"5"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := evaluateCSV(t, tt.input, data, Options{})
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("matched lines mismatch\nGot: %v\nWant: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompile_Decimal(t *testing.T) {
+	const data = `1,1234.50
+2,0.30000000000000004
+3,0.3
+4,-12.00
+5,n/a
+`
+
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "Equal ignores trailing zeros", input: "(decimal,1,eq,1234.5)", want: []string{"1"}},
+		// as floats, 0.1 + 0.2 would equal 0.3
+		{name: "Equal is exact", input: "(decimal,1,eq,0.30)", want: []string{"3"}},
+		{name: "Greater than", input: "(dec,1,>,0.3)", want: []string{"1", "2"}},
+		{name: "In", input: "(decimal,1,in,[-12,1234.500])", want: []string{"1", "4"}},
+		{name: "Range", input: "(decimal,1,between,-12..<0.30000000000000004)", want: []string{"3", "4"}},
+		{name: "Unreadable", input: "(decimal,1,missing)", want: []string{"5"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := evaluateCSV(t, tt.input, data, Options{})
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("matched lines mismatch\nGot: %v\nWant: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompile_LargeIntegers(t *testing.T) {
+	// 2^53 + 1 and 2^53 are the same float64, but not the same integer
+	const data = `1,9007199254740993
+2,9007199254740992
+3,18446744073709551615
+4,-9007199254740993
+5,1.5
+`
+
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "Int", input: "(int,1,eq,9007199254740993)", want: []string{"1"}},
+		// integers are read as exact decimals, so data beyond an int compares as well
+		{name: "Int greater than", input: "(int,1,>,9007199254740992)", want: []string{"1", "3"}},
+		{name: "Int64", input: "(int64,1,<,-9007199254740992)", want: []string{"4"}},
+		{name: "Beyond int", input: "(number,1,>=,18446744073709551615)", want: []string{"3"}},
+		// data that no uint64 holds still compares with a literal beyond int
+		{name: "Beyond int against negative data", input: "(number,1,<,9223372036854775808)", want: []string{"1", "2", "4", "5"}},
+		{name: "Beyond uint64", input: "(number,1,<,-18446744073709551616)", want: nil},
+		{name: "Uint64 spelled out", input: "(uint64,1,in,[9007199254740992,18446744073709551615])", want: []string{"2", "3"}},
+		{name: "Float", input: "(float,1,>,2)", want: []string{"1", "2", "3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := evaluateCSV(t, tt.input, data, Options{})
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("matched lines mismatch\nGot: %v\nWant: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompile_IntegerLiterals(t *testing.T) {
+	const data = `1,3
+2,3.5
+3,1.5
+4,2.0
+5,-5
+6,n/a
+`
+
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "Greater than matches fractions", input: "(number,1,>,3)", want: []string{"2"}},
+		{name: "Equal matches trailing zeros", input: "(int,1,==,2)", want: []string{"4"}},
+		{name: "Not equal keeps fractions", input: "(number,1,!=,3)", want: []string{"2", "3", "4", "5"}},
+		{name: "Range", input: "(number,1,between,1..2)", want: []string{"3", "4"}},
+		{name: "Set", input: "(number,1,in,[3,-5])", want: []string{"1", "5"}},
+		{name: "Fraction literal", input: "(number,1,<,2.5)", want: []string{"3", "4", "5"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := evaluateCSV(t, tt.input, data, Options{})
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("matched lines mismatch\nGot: %v\nWant: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompile_TimeLayout(t *testing.T) {
+	got := evaluateLines(t, "(datetime,4,less_than,2025-03-20T00:00:00Z)", Options{TimeLayout: "2006-01-02T15:04:05Z07:00"})
+	// the data does not match the layout, so nothing can be read
+	if len(got) != 0 {
+		t.Errorf("expected no matches, got %v", got)
+	}
+}
+
+func TestCompile_Error(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr error
+	}{
+		{name: "Operator not valid for value type", input: "(number,0,contain,3)", wantErr: filter.ErrInvalidOperator},
+		{name: "Operator not valid for string", input: "(string,1,less_than,a)", wantErr: filter.ErrInvalidOperator},
+		{name: "Unknown value type", input: "(bytes,1,equal,a)"},
+		{name: "Unknown operator", input: "(string,1,resembles,a)"},
+		{name: "Invalid glob", input: `(string,1,glob,"[a-")`, wantErr: filter.ErrInvalidPattern},
+		{name: "Unknown operator modifier", input: "(string,1,contain:x,a)"},
+		{name: "Distance on a non-fuzzy operator", input: "(string,1,contain:2,a)"},
+		{name: "Fuzzy number", input: "(number,0,fuzzy:1,3)", wantErr: filter.ErrInvalidOperator},
+		{name: "Match mode on a number", input: "(number,0,ieq,1)", wantErr: filter.ErrInvalidMatchMode},
+		{name: "List with a non-set operator", input: "(string,1,equal,[a,b])", wantErr: filter.ErrInvalidOperator},
+		{name: "Datetime set", input: "(datetime,4,in,[2025-03-20 10:00:00])", wantErr: filter.ErrInvalidOperator},
+		{name: "Invalid number in list", input: "(number,0,in,[1,two])"},
+		{name: "Reversed range", input: "(int,0,between,3..1)", wantErr: filter.ErrInvalidRange},
+		{name: "Range without upper end", input: "(int,0,between,3..)"},
+		{name: "Range without separator", input: "(int,0,between,3)"},
+		{name: "String range", input: "(string,1,between,a..b)", wantErr: filter.ErrInvalidOperator},
+		{name: "Range as a list", input: "(int,0,between,[1,2])", wantErr: filter.ErrInvalidOperator},
+		{name: "Value for a presence operator", input: "(string,1,exists,a)"},
+		{name: "Empty number", input: "(number,0,is_empty)", wantErr: filter.ErrInvalidOperator},
+		{name: "Invalid number", input: "(number,0,equal,three)"},
+		{name: "Invalid pattern", input: `(string,1,=~,"[a-")`, wantErr: filter.ErrInvalidPattern},
+		{name: "Pattern on a number", input: `(number,0,=~,1)`, wantErr: filter.ErrInvalidOperator},
+		{name: "Invalid datetime", input: "(datetime,4,equal,yesterday)"},
+		{name: "Invalid bool", input: "(bool,1,equal,maybe)"},
+		{name: "Invalid duration", input: "(duration,1,>,fast)"},
+		{name: "Bare duration without a unit", input: "(duration,1,>,500)"},
+		{name: "Duration contain", input: "(duration,1,contain,1s)", wantErr: filter.ErrInvalidOperator},
+		{name: "Invalid IP address", input: "(ip,1,eq,10.0.0.256)"},
+		{name: "Invalid prefix", input: "(ip,1,in_cidr,[10.0.0.0/33])"},
+		{name: "IP comparison", input: "(ip,1,<,10.0.0.1)", wantErr: filter.ErrInvalidOperator},
+		{name: "CIDR on a string", input: "(string,1,in_cidr,10.0.0.0/8)", wantErr: filter.ErrInvalidOperator},
+		{name: "Invalid version", input: "(semver,1,>,2.x)"},
+		{name: "Invalid decimal", input: "(decimal,1,eq,12.5 EUR)"},
+		{name: "Decimal contain", input: "(decimal,1,contain,12)", wantErr: filter.ErrInvalidOperator},
+		{name: "Caret on a number", input: "(number,0,^,1)", wantErr: filter.ErrInvalidOperator},
+		{name: "Negative uint64", input: "(uint64,0,eq,-1)"},
+		{name: "Fraction as int64", input: "(int64,0,eq,1.5)"},
+		{name: "Bool comparison", input: "(bool,1,less_than,true)", wantErr: filter.ErrInvalidOperator},
+		{name: "Error in nested filter", input: "(string,1,equal,a) and ((string,1,equal,b) or (number,0,contain,3))", wantErr: filter.ErrInvalidOperator},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+			_, err = Compile(expr, reader.NewCSVReader(), Options{})
+			if err == nil {
+				t.Fatalf("expected error for %q", tt.input)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCompile_ColumnNames(t *testing.T) {
+	expr, err := Parse("(string,who,contain,o) and (int,idx,<,3)")
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	csvReader := reader.NewCSVReader()
+	csvReader.SetHeader([]string{"idx", "who", "what", "whom"})
+	tree, err := Compile(expr, csvReader, Options{})
+	if err != nil {
+		t.Fatalf("unexpected compile error: %v", err)
+	}
+
+	var matched []string
+	who := csvReader.StringGetter("who")
+	csvReader.InputStream(strings.NewReader(sampleCSV))
+	for csvReader.LoadNextLine() {
+		if tree.Evaluate() {
+			v, _ := who()
+			matched = append(matched, v)
+		}
+	}
+	if strings.Join(matched, ",") != "monkey,dog" {
+		t.Errorf("matched lines mismatch\nGot: %v\nWant: [monkey dog]", matched)
+	}
+
+	expr, err = Parse("(string,country,equal,RS)")
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	if _, err := Compile(expr, csvReader, Options{}); !errors.Is(err, reader.ErrUnknownColumn) {
+		t.Errorf("expected %v, got %v", reader.ErrUnknownColumn, err)
+	}
+	if _, err := Compile(expr, reader.NewCSVReader(), Options{}); !errors.Is(err, reader.ErrNoHeader) {
+		t.Errorf("expected %v, got %v", reader.ErrNoHeader, err)
+	}
+
+	// the header line is not loaded at compile time, so a misspelled name is only reported by the reader
+	expr, err = Parse("(string,nmae,not_exists)")
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	headerReader := reader.NewCSVReaderWithHeader()
+	tree, err = Compile(expr, headerReader, Options{})
+	if err != nil {
+		t.Fatalf("unexpected compile error: %v", err)
+	}
+	headerReader.InputStream(strings.NewReader("idx,name\n1,monkey\n"))
+	for headerReader.LoadNextLine() {
+		if tree.Evaluate() {
+			t.Errorf("expected no line to be loaded")
+		}
+	}
+	if err := headerReader.Err(); !errors.Is(err, reader.ErrUnknownColumn) {
+		t.Errorf("expected %v, got %v", reader.ErrUnknownColumn, err)
+	}
+}
@@ -0,0 +1,82 @@
+package filterexpr
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// SyntaxError describes a malformed filter expression and where in the input it was found.
+type SyntaxError struct {
+	Input    string // the whole expression
+	Offset   int    // byte offset of the problem in Input
+	Expected string // what was expected at Offset, empty when Msg describes the problem
+	Found    string // what was found at Offset instead
+	Msg      string
+}
+
+func (e *SyntaxError) Error() string {
+	line, column := e.Position()
+	where := fmt.Sprintf("column %d", column)
+	if line > 1 {
+		where = fmt.Sprintf("line %d, %s", line, where)
+	}
+
+	if e.Expected != "" {
+		return fmt.Sprintf("filterexpr: syntax error at %s: expected %s, found %s", where, e.Expected, e.Found)
+	}
+	return fmt.Sprintf("filterexpr: syntax error at %s: %s", where, e.Msg)
+}
+
+// Position returns the 1-based line and column (in characters) of Offset.
+func (e *SyntaxError) Position() (line, column int) {
+	start, _ := e.lineBounds()
+	line = strings.Count(e.Input[:start], "\n") + 1
+	column = utf8.RuneCountInString(e.Input[start:e.offset()]) + 1
+	return line, column
+}
+
+// Render returns the line of the expression that contains the problem with a ^ under it:
+//
+//	(string,1,contain
+//	                 ^
+func (e *SyntaxError) Render() string {
+	start, end := e.lineBounds()
+	text := e.Input[start:end]
+
+	var caret strings.Builder
+	for _, r := range e.Input[start:e.offset()] {
+		// keep tabs so the caret lines up with tab-indented input
+		if r == '\t' {
+			caret.WriteRune('\t')
+		} else {
+			caret.WriteRune(' ')
+		}
+	}
+	caret.WriteRune('^')
+
+	return text + "\n" + caret.String()
+}
+
+// offset returns Offset clamped to the bounds of Input.
+func (e *SyntaxError) offset() int {
+	return max(0, min(e.Offset, len(e.Input)))
+}
+
+// lineBounds returns the byte range of the line that contains Offset, without the newline.
+func (e *SyntaxError) lineBounds() (int, int) {
+	offset := e.offset()
+	start := strings.LastIndexByte(e.Input[:offset], '\n') + 1
+	end := strings.IndexByte(e.Input[offset:], '\n')
+	if end < 0 {
+		return start, len(e.Input)
+	}
+	return start, offset + end
+}
+
+// describeToken returns how a token is shown in the Found part of a SyntaxError.
+func describeToken(tok Token) string {
+	return fmt.Sprintf("%q", tok.Value)
+}
+
+const endOfInput = "end of input"
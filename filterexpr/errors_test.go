@@ -0,0 +1,86 @@
+package filterexpr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse_SyntaxError(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		offset  int
+		message string
+		render  string
+	}{
+		{
+			name:    "Missing closing parenthesis",
+			input:   "(string,1,contain,banana",
+			offset:  24,
+			message: `filterexpr: syntax error at column 25: expected ")", found end of input`,
+			render:  "(string,1,contain,banana\n                        ^",
+		},
+		{
+			name:    "Too few parts",
+			input:   "(int,3,==,1000) and (string,1,contain)",
+			offset:  20,
+			message: "filterexpr: syntax error at column 21: filter must have 4 parts (type,key,operator,value), found 3",
+			render:  "(int,3,==,1000) and (string,1,contain)\n                    ^",
+		},
+		{
+			name:    "Missing operator",
+			input:   "(int,3,==,1000) (string,1,contain,a)",
+			offset:  16,
+			message: `filterexpr: syntax error at column 17: expected and, or or end of input, found "("`,
+			render:  "(int,3,==,1000) (string,1,contain,a)\n                ^",
+		},
+		{
+			name:    "Unterminated quote",
+			input:   `(string,1,equal,"banana)`,
+			offset:  16,
+			message: "filterexpr: syntax error at column 17: unterminated quoted value",
+			render:  "(string,1,equal,\"banana)\n                ^",
+		},
+		{
+			name:    "Invalid unicode escape",
+			input:   `(string,1,equal,caf\u00zz)`,
+			offset:  19,
+			message: `filterexpr: syntax error at column 20: invalid unicode escape \u00zz`,
+			render:  "(string,1,equal,caf\\u00zz)\n                   ^",
+		},
+		{
+			name:    "Column counts characters, not bytes",
+			input:   "(string,1,equal,café) or",
+			offset:  25,
+			message: `filterexpr: syntax error at column 25: expected "(", found end of input`,
+			render:  "(string,1,equal,café) or\n                        ^",
+		},
+		{
+			name:    "Multi-line input",
+			input:   "(string,1,equal,a) or\n\t(string,2,equal,b",
+			offset:  40,
+			message: `filterexpr: syntax error at line 2, column 19: expected ")", found end of input`,
+			render:  "\t(string,2,equal,b\n\t                 ^",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.input)
+
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("expected *SyntaxError, got %v", err)
+			}
+			if syntaxErr.Offset != tt.offset {
+				t.Errorf("offset mismatch\nGot: %d\nWant: %d", syntaxErr.Offset, tt.offset)
+			}
+			if syntaxErr.Error() != tt.message {
+				t.Errorf("message mismatch\nGot: %s\nWant: %s", syntaxErr.Error(), tt.message)
+			}
+			if syntaxErr.Render() != tt.render {
+				t.Errorf("render mismatch\nGot:\n%s\nWant:\n%s", syntaxErr.Render(), tt.render)
+			}
+		})
+	}
+}
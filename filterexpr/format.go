@@ -0,0 +1,380 @@
+package filterexpr
+
+import (
+	"errors"
+	"fejsal/filter"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Format returns the canonical text of e.
+//
+// Logical operators are always spelled and, or and not, value types and operators by their canonical names
+// (such as number and less_than for int and <), parentheses are only added where precedence requires them,
+// and values are quoted only when they could not be read back verbatim. Unknown type or operator spellings
+// are written as they are. The result parses back into a tree equal to e, as long as e only uses canonical names.
+func Format(e *Expr) string {
+	var b strings.Builder
+	writeExpr(&b, e)
+	return b.String()
+}
+
+// String implements fmt.Stringer using Format.
+func (e *Expr) String() string {
+	return Format(e)
+}
+
+// String returns the filter as a (type,key,operator,value) tuple.
+func (f RawFilter) String() string {
+	var b strings.Builder
+	writeFilter(&b, f)
+	return b.String()
+}
+
+// precedence returns how tightly e binds, so a child with a lower precedence than its parent needs parentheses.
+func precedence(e *Expr) int {
+	if e.Type == NodeOp {
+		if normalizeOp(e.Op) == OpOr {
+			return 1
+		}
+		return 2
+	}
+	return 3
+}
+
+func writeExpr(b *strings.Builder, e *Expr) {
+	if e == nil {
+		return
+	}
+
+	switch e.Type {
+	case NodeFilter:
+		writeFilter(b, e.Filter)
+	case NodeNot:
+		b.WriteString("not ")
+		writeOperand(b, e.Left, precedence(e.Left) < 3)
+	case NodeOp:
+		// operators group from the left, so a right operand of the same precedence needs parentheses
+		writeOperand(b, e.Left, precedence(e.Left) < precedence(e))
+		b.WriteString(" " + normalizeOp(e.Op) + " ")
+		writeOperand(b, e.Right, precedence(e.Right) <= precedence(e))
+	}
+}
+
+func writeOperand(b *strings.Builder, e *Expr, parenthesize bool) {
+	if parenthesize {
+		b.WriteByte('(')
+	}
+	writeExpr(b, e)
+	if parenthesize {
+		b.WriteByte(')')
+	}
+}
+
+func writeFilter(b *strings.Builder, f RawFilter) {
+	var key string
+	switch idx := f.Index.(type) {
+	case int:
+		key = strconv.Itoa(idx)
+	case string:
+		key = quoteValue(idx)
+		// a string key that looks like a column index must stay a string
+		if _, err := strconv.Atoi(idx); err == nil {
+			key = quote(idx)
+		}
+	default:
+		key = quoteValue(fmt.Sprint(idx))
+	}
+
+	b.WriteByte('(')
+	b.WriteString(quoteValue(canonicalValueType(f.ValueType)))
+	b.WriteByte(',')
+	b.WriteString(key)
+	b.WriteByte(',')
+	b.WriteString(quoteValue(canonicalOperator(f.Operator)))
+	switch {
+	case f.Values != nil:
+		b.WriteByte(',')
+		writeList(b, f.Values)
+	case f.Value == "" && isPresenceSpelling(f.Operator):
+		// (type,key,operator) for operators that take no value
+	default:
+		b.WriteByte(',')
+		b.WriteString(quoteValue(f.Value))
+	}
+	b.WriteByte(')')
+}
+
+// writeList writes a list value, quoting the values that would otherwise close the list.
+func writeList(b *strings.Builder, values []string) {
+	b.WriteByte('[')
+	for i, value := range values {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		if strings.Contains(value, "]") {
+			b.WriteString(quote(value))
+		} else {
+			b.WriteString(quoteValue(value))
+		}
+	}
+	b.WriteByte(']')
+}
+
+// quoteValue returns s unchanged if the tokenizer reads it back as the same single value, and quoted otherwise.
+func quoteValue(s string) string {
+	if s == "" || strings.TrimSpace(s) != s || s[0] == '"' || s[0] == '\'' || s[0] == '[' {
+		return quote(s)
+	}
+	if strings.ContainsAny(s, "(),\\") || strings.ContainsFunc(s, func(r rune) bool { return !unicode.IsPrint(r) && r != ' ' }) {
+		return quote(s)
+	}
+	if _, ok := splitKeywords(s, 0); ok {
+		return quote(s)
+	}
+	return s
+}
+
+// quote wraps s in double quotes using only the escapes the tokenizer understands.
+func quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			switch {
+			case unicode.IsPrint(r) || r == ' ':
+				b.WriteRune(r)
+			case r > 0xFFFF:
+				fmt.Fprintf(&b, `\U%08X`, r)
+			default:
+				fmt.Fprintf(&b, `\u%04X`, r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// FormatTree returns the canonical text of a compiled tree, see Decompile and Format.
+func FormatTree(t *filter.FTree, opts Options) (string, error) {
+	expr, err := Decompile(t, opts)
+	if err != nil {
+		return "", err
+	}
+	return Format(expr), nil
+}
+
+// Decompile turns a filter.FTree back into an Expr.
+//
+// Every filter set of the tree must implement filter.Describer and have a Key, which is the case for
+// trees built by Compile. A set holding several filters becomes a chain of its filters joined by the
+// set's condition. Datetime values are written using the layout their set was compiled with (filter.SetInfo.Format),
+// or else opts.TimeLayout, which is also recorded as their Layout.
+func Decompile(t *filter.FTree, opts Options) (*Expr, error) {
+	if t == nil {
+		return nil, errors.New("filterexpr: nil tree")
+	}
+
+	var expr *Expr
+	if t.FilterSet != nil {
+		describer, ok := t.FilterSet.(filter.Describer)
+		if !ok {
+			return nil, fmt.Errorf("filterexpr: filter set %T cannot be described", t.FilterSet)
+		}
+		var err error
+		expr, err = decompileSet(describer.Describe(), opts)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		var op string
+		switch t.Condition {
+		case filter.ConditionAnd:
+			op = OpAnd
+		case filter.ConditionOr:
+			op = OpOr
+		default:
+			return nil, fmt.Errorf("filterexpr: unknown condition %q", t.Condition)
+		}
+
+		left, err := Decompile(t.Left, opts)
+		if err != nil {
+			return nil, err
+		}
+		right, err := Decompile(t.Right, opts)
+		if err != nil {
+			return nil, err
+		}
+		expr = &Expr{Type: NodeOp, Op: op, Left: left, Right: right}
+	}
+
+	if t.Negate {
+		expr = &Expr{Type: NodeNot, Left: expr}
+	}
+	return expr, nil
+}
+
+func decompileSet(set filter.SetInfo, opts Options) (*Expr, error) {
+	if set.Key == nil {
+		return nil, errors.New("filterexpr: filter set has no Key")
+	}
+	if len(set.Filters) == 0 {
+		return nil, errors.New("filterexpr: filter set has no filters")
+	}
+
+	if layout, ok := set.Format.(string); ok && layout != "" {
+		opts.TimeLayout = layout
+	}
+
+	op := OpAnd
+	if set.Condition == filter.ConditionOr {
+		op = OpOr
+	}
+
+	var expr *Expr
+	for _, info := range set.Filters {
+		leaf := &Expr{
+			Type: NodeFilter,
+			Filter: RawFilter{
+				ValueType: formatValueType(info),
+				Index:     set.Key,
+				Operator:  formatOperator(info),
+			},
+		}
+		if err := decompileValue(&leaf.Filter, info, opts); err != nil {
+			return nil, err
+		}
+		if info.ValueType == filter.ValueTypeDatetime {
+			leaf.Filter.Layout = opts.timeLayout()
+		}
+		if expr == nil {
+			expr = leaf
+		} else {
+			expr = &Expr{Type: NodeOp, Op: op, Left: expr, Right: leaf}
+		}
+	}
+	return expr, nil
+}
+
+// decompileValue sets the value of raw from info the way compileFilter reads it back.
+func decompileValue(raw *RawFilter, info filter.FilterInfo, opts Options) error {
+	switch {
+	case isPresenceOperator(info.Operator):
+		return nil
+	case info.Values != nil:
+		raw.Values = make([]string, len(info.Values))
+		for i, value := range info.Values {
+			formatted, err := formatValue(value, opts)
+			if err != nil {
+				return err
+			}
+			raw.Values[i] = formatted
+		}
+		return nil
+	case info.Operator == filter.OperatorBetween:
+		lower, err := formatValue(info.Value, opts)
+		if err != nil {
+			return err
+		}
+		upper, err := formatValue(info.Upper, opts)
+		if err != nil {
+			return err
+		}
+		raw.Value = formatRange(lower, upper, info.Bounds)
+		return nil
+	}
+
+	value, err := formatValue(info.Value, opts)
+	if err != nil {
+		return err
+	}
+	raw.Value = value
+	return nil
+}
+
+// formatValue writes a filter value the way Compile reads it back.
+func formatValue(value any, opts Options) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case int:
+		return strconv.Itoa(v), nil
+	case float32:
+		return formatFloat(float64(v), 32), nil
+	case float64:
+		return formatFloat(v, 64), nil
+	case time.Time:
+		return v.Format(opts.timeLayout()), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case time.Duration:
+		return v.String(), nil
+	case netip.Addr:
+		return v.String(), nil
+	case netip.Prefix:
+		return v.String(), nil
+	case filter.Semver:
+		return v.String(), nil
+	case filter.Decimal:
+		return v.String(), nil
+	}
+	return "", fmt.Errorf("filterexpr: cannot format value %v of type %T", value, value)
+}
+
+// formatValueType returns the lower-cased value type of a filter, or the spelling that compiles its numbers
+// back to the same Go type, as a number that fits an int would otherwise come back as an int.
+func formatValueType(info filter.FilterInfo) string {
+	value := info.Value
+	if len(info.Values) > 0 {
+		value = info.Values[0]
+	}
+	switch value.(type) {
+	case int64:
+		return "int64"
+	case uint64:
+		return "uint64"
+	}
+	return strings.ToLower(string(info.ValueType))
+}
+
+// formatRange writes the ends of a Between filter the way splitRange reads them back.
+func formatRange(lower, upper string, bounds filter.Bounds) string {
+	var b strings.Builder
+	b.WriteString(lower)
+	if bounds&filter.BoundsExcludeLower != 0 {
+		b.WriteByte('<')
+	}
+	b.WriteString(rangeSeparator)
+	if bounds&filter.BoundsExcludeUpper != 0 {
+		b.WriteByte('<')
+	}
+	b.WriteString(upper)
+	return b.String()
+}
+
+// formatFloat always includes a decimal point or exponent, so the value is compiled back as a float.
+func formatFloat(v float64, bitSize int) string {
+	s := strconv.FormatFloat(v, 'g', -1, bitSize)
+	if !strings.ContainsAny(s, ".eEIN") {
+		s += ".0"
+	}
+	return s
+}
@@ -0,0 +1,222 @@
+package filterexpr
+
+import (
+	"fejsal/reader"
+	"reflect"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	a := leaf("string", 1, "contain", "banana")
+	b := leaf("time", 2, ">", "2025-03-20 00:00:00")
+	c := leaf("int", 3, "==", "1000")
+
+	tests := []struct {
+		name string
+		expr *Expr
+		want string
+	}{
+		{
+			name: "Single filter",
+			expr: a,
+			want: "(string,1,contain,banana)",
+		},
+		{
+			name: "AND inside OR needs no parentheses",
+			expr: op(OpOr, a, op(OpAnd, b, c)),
+			want: "(string,1,contain,banana) or (datetime,2,greater_than,2025-03-20 00:00:00) and (number,3,equal,1000)",
+		},
+		{
+			name: "OR inside AND is parenthesized",
+			expr: op(OpAnd, op(OpOr, a, b), c),
+			want: "((string,1,contain,banana) or (datetime,2,greater_than,2025-03-20 00:00:00)) and (number,3,equal,1000)",
+		},
+		{
+			name: "Left chain needs no parentheses",
+			expr: op(OpOr, op(OpOr, a, b), c),
+			want: "(string,1,contain,banana) or (datetime,2,greater_than,2025-03-20 00:00:00) or (number,3,equal,1000)",
+		},
+		{
+			name: "Right operand of the same operator is parenthesized",
+			expr: op(OpOr, a, op(OpOr, b, c)),
+			want: "(string,1,contain,banana) or ((datetime,2,greater_than,2025-03-20 00:00:00) or (number,3,equal,1000))",
+		},
+		{
+			name: "Symbolic operators are spelled out",
+			expr: op("&&", a, op("||", b, c)),
+			want: "(string,1,contain,banana) and ((datetime,2,greater_than,2025-03-20 00:00:00) or (number,3,equal,1000))",
+		},
+		{
+			name: "Negated filter and group",
+			expr: op(OpAnd, not(a), not(op(OpOr, b, c))),
+			want: "not (string,1,contain,banana) and not ((datetime,2,greater_than,2025-03-20 00:00:00) or (number,3,equal,1000))",
+		},
+		{
+			name: "Values are quoted only when needed",
+			expr: op(OpOr,
+				leaf("string", "message", "equal", "banana, ripe"),
+				op(OpOr, leaf("string", "3", "equal", " padded"), leaf("string", "msg", "equal", "and"))),
+			want: `(string,message,equal,"banana, ripe") or ((string,"3",equal," padded") or (string,msg,equal,"and"))`,
+		},
+		{
+			name: "Names are canonical",
+			expr: op(OpOr,
+				op(OpOr, leaf("STR", 0, "LESS_THAN", "3"), leaf("int", 0, "<", "3")),
+				op(OpOr, leaf("Int64", 0, "icontain", "a"), leaf("bytes", 0, "resembles", "a"))),
+			want: "(string,0,less_than,3) or (number,0,less_than,3) or ((int64,0,contain:i,a) or (bytes,0,resembles,a))",
+		},
+		{
+			name: "Escapes",
+			expr: leaf("string", 0, "equal", "say \"hi\"\n\\\x01"),
+			want: `(string,0,equal,"say \"hi\"\n\\\u0001")`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Format(tt.expr)
+			if got != tt.want {
+				t.Errorf("format result mismatch\nGot: %s\nWant: %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormat_RoundTrip(t *testing.T) {
+	inputs := []string{
+		"((string,1,contain,banana)or(time,2,>,2025-03-20 00:00:00))and(int,3,==,1000)",
+		"(string,1,contain,banana) || (time,2,>,2025-03-20 00:00:00) && (int,3,==,1000)",
+		"(a,1,b,c) or ((a,2,b,c) or ((a,3,b,c) and (a,4,b,c)))",
+		"!(a,1,b,c) and not not ((a,2,b,c) or not (a,3,b,c))",
+		`(string,"007",equal,"x) or (y") and (string,'',equal,'')`,
+		`(string,msg,equal,"tab\there") or (string,msg,equal,"quote \" and \\ slash")`,
+		`(string,msg,equal,"and not") or (string,msg,contain,"é\U0001F34C")`,
+		`(string,user,in,[a, "b,c", 'd]', "[e", and]) or (int,0,!in,[]) or (string,msg,equal,"[x]")`,
+		`(string,1,exists) and (time,2,missing) or (string,3,empty,"")`,
+	}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			expr, err := Parse(input)
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+			formatted := Format(expr)
+			again, err := Parse(formatted)
+			if err != nil {
+				t.Fatalf("formatted expression %s does not parse: %v", formatted, err)
+			}
+			canonicalize(expr)
+			if !reflect.DeepEqual(expr, again) {
+				t.Errorf("round trip mismatch for %s\nGot: %#v\nWant: %#v", formatted, again, expr)
+			}
+		})
+	}
+}
+
+// canonicalize replaces the value type and operator spellings of every filter of e with the names Format writes.
+func canonicalize(e *Expr) {
+	if e == nil {
+		return
+	}
+	if e.Type == NodeFilter {
+		e.Filter.ValueType = canonicalValueType(e.Filter.ValueType)
+		e.Filter.Operator = canonicalOperator(e.Filter.Operator)
+	}
+	canonicalize(e.Left)
+	canonicalize(e.Right)
+}
+
+func TestFormatTree(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "Compiled tree",
+			input: "((string,3,equal,banana) or (string,message,contain,'a,b')) and not (number,0,LESS_THAN,3) and (number,5,greater_than_or_equal,1.0)",
+			want:  `((string,3,equal,banana) or (string,message,contain,"a,b")) and not (number,0,less_than,3) and (number,5,greater_than_or_equal,1.0)`,
+		},
+		{
+			name:  "Datetime uses the time layout",
+			input: "not ((DATETIME,4,less_than,2025-03-20 10:00:00) or (datetime,4,greater_than,2025-03-21 00:00:00))",
+			want:  "not ((datetime,4,less_than,2025-03-20 10:00:00) or (datetime,4,greater_than,2025-03-21 00:00:00))",
+		},
+		{
+			name:  "Lists",
+			input: "(string,1,in:i,[Dog, 'a]']) and (int,0,not_in,[1,2]) or (float,5,in,[1,2.5])",
+			want:  `(string,1,in:i,[Dog,"a]"]) and (number,0,not_in,[1,2]) or (number,5,in,[1.0,2.5])`,
+		},
+		{
+			name:  "Ranges",
+			input: "(time,4,between,2025-03-20..<2025-03-21) or (int,0,between, 1 <..< 3) or (float,5,between,0.5..1)",
+			want:  "(datetime,4,between,2025-03-20 00:00:00..<2025-03-21 00:00:00) or (number,0,between,1<..<3) or (number,5,between,0.5..1.0)",
+		},
+		{
+			name:  "Presence",
+			input: "(string,1,exists) and (number,0,missing) or (str,2,is_empty)",
+			want:  "(string,1,exists) and (number,0,not_exists) or (string,2,is_empty)",
+		},
+		{
+			name:  "Match modes",
+			input: "(string,1,icontain,MON) or (string,1,equal:a,dóg) or (string,1,=~:IA,^i$)",
+			want:  "(string,1,contain:i,MON) or (string,1,equal:a,dóg) or (string,1,match:ia,^i$)",
+		},
+		{
+			name:  "Duration",
+			input: "(dur,1,>,1500ms) or (duration,1,between,1m..<2m3s)",
+			want:  "(duration,1,greater_than,1.5s) or (duration,1,between,1m0s..<2m3s)",
+		},
+		{
+			name:  "IP",
+			input: "(ip,1,cidr,10.1.2.3/8) and (ip,1,!cidr,[10.0.0.0/24, fd00::1]) or (ip,1,ne,::ffff:10.0.0.1)",
+			want:  "(ip,1,in_cidr,[10.0.0.0/8]) and (ip,1,not_in_cidr,[10.0.0.0/24,fd00::1/128]) or (ip,1,not_equal,::ffff:10.0.0.1)",
+		},
+		{
+			name:  "Semver",
+			input: "(version,1,^,v1.2) or (semver,1,~>,1.2.3-beta.1+build.5)",
+			want:  "(semver,1,caret,1.2.0) or (semver,1,tilde,1.2.3-beta.1+build.5)",
+		},
+		{
+			name:  "Decimal",
+			input: "(dec,1,>=,1000.00) and (decimal,1,in,[1234.50,-0.070,1.5e3])",
+			want:  "(decimal,1,greater_than_or_equal,1000) and (decimal,1,in,[1234.5,-0.07,1500])",
+		},
+		{
+			name:  "64-bit integers",
+			input: "(int64,0,>,9007199254740993) and (uint64,0,in,[1,18446744073709551615]) or (number,0,<,18446744073709551615)",
+			want:  "(int64,0,greater_than,9007199254740993) and (uint64,0,in,[1,18446744073709551615]) or (number,0,less_than,18446744073709551615)",
+		},
+		{
+			name:  "Bool",
+			input: "(boolean,1,eq,YES) and (bool,2,ne,0)",
+			want:  "(bool,1,equal,true) and (bool,2,not_equal,false)",
+		},
+		{
+			name:  "Fuzzy",
+			input: "(string,1,FUZZY,monkee) or (string,1,fuzzy:2:WI,dgo) or (string,1,ifuzzy:d:1,dgo)",
+			want:  "(string,1,fuzzy,monkee) or (string,1,fuzzy:2:iw,dgo) or (string,1,fuzzy:1:id,dgo)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+			tree, err := Compile(expr, reader.NewCSVReaderWithHeader(), Options{})
+			if err != nil {
+				t.Fatalf("unexpected compile error: %v", err)
+			}
+			got, err := FormatTree(tree, Options{})
+			if err != nil {
+				t.Fatalf("unexpected format error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("format result mismatch\nGot: %s\nWant: %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package filterexpr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fejsal/filter"
+	"fejsal/reader"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// jsonExpr is the JSON form of an Expr. Every node has a type, which is one of
+//
+//	{"type":"and","left":{...},"right":{...}}
+//	{"type":"or","left":{...},"right":{...}}
+//	{"type":"not","operand":{...}}
+//	{"type":"filter","valueType":"datetime","key":4,"operator":"less_than","value":"2025-03-20","layout":"2006-01-02"}
+//
+// A filter key is a number for a column index and a string for a named key. A filter value is always
+// written as a string, but a number or boolean is accepted when decoding. A list value such as [a,b,c]
+// is an array of such values. The layout is optional.
+type jsonExpr struct {
+	Type      string          `json:"type"`
+	Left      *Expr           `json:"left,omitempty"`
+	Right     *Expr           `json:"right,omitempty"`
+	Operand   *Expr           `json:"operand,omitempty"`
+	ValueType string          `json:"valueType,omitempty"`
+	Key       json.RawMessage `json:"key,omitempty"`
+	Operator  string          `json:"operator,omitempty"`
+	Value     json.RawMessage `json:"value,omitempty"`
+	Layout    string          `json:"layout,omitempty"`
+}
+
+const (
+	jsonTypeFilter = "filter"
+	jsonTypeNot    = "not"
+)
+
+// MarshalJSON implements json.Marshaler.
+func (e *Expr) MarshalJSON() ([]byte, error) {
+	switch e.Type {
+	case NodeFilter:
+		key, err := json.Marshal(e.Filter.Index)
+		if err != nil {
+			return nil, err
+		}
+		var value []byte
+		if e.Filter.Values != nil {
+			value, err = json.Marshal(e.Filter.Values)
+		} else {
+			value, err = json.Marshal(e.Filter.Value)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(jsonExpr{
+			Type:      jsonTypeFilter,
+			ValueType: e.Filter.ValueType,
+			Key:       key,
+			Operator:  e.Filter.Operator,
+			Value:     value,
+			Layout:    e.Filter.Layout,
+		})
+	case NodeOp:
+		return json.Marshal(jsonExpr{Type: normalizeOp(e.Op), Left: e.Left, Right: e.Right})
+	case NodeNot:
+		return json.Marshal(jsonExpr{Type: jsonTypeNot, Operand: e.Left})
+	}
+	return nil, fmt.Errorf("filterexpr: unknown node type %d", e.Type)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (e *Expr) UnmarshalJSON(data []byte) error {
+	var node jsonExpr
+	if err := json.Unmarshal(data, &node); err != nil {
+		return err
+	}
+
+	switch typ := normalizeOp(strings.ToLower(node.Type)); typ {
+	case jsonTypeFilter:
+		if node.ValueType == "" || node.Operator == "" {
+			return errors.New(`filterexpr: filter node needs "valueType" and "operator"`)
+		}
+		index, err := decodeJSONKey(node.Key)
+		if err != nil {
+			return err
+		}
+		raw := RawFilter{
+			ValueType: node.ValueType,
+			Index:     index,
+			Operator:  node.Operator,
+			Layout:    node.Layout,
+		}
+		if bytes.HasPrefix(bytes.TrimSpace(node.Value), []byte("[")) {
+			raw.Values, err = decodeJSONList(node.Value)
+		} else {
+			raw.Value, err = decodeJSONValue(node.Value)
+		}
+		if err != nil {
+			return err
+		}
+		*e = Expr{Type: NodeFilter, Filter: raw}
+	case OpAnd, OpOr:
+		if node.Left == nil || node.Right == nil {
+			return fmt.Errorf(`filterexpr: %s node needs "left" and "right"`, typ)
+		}
+		*e = Expr{Type: NodeOp, Op: typ, Left: node.Left, Right: node.Right}
+	case jsonTypeNot:
+		if node.Operand == nil {
+			return errors.New(`filterexpr: not node needs "operand"`)
+		}
+		*e = Expr{Type: NodeNot, Left: node.Operand}
+	default:
+		return fmt.Errorf("filterexpr: unknown node type %q", node.Type)
+	}
+	return nil
+}
+
+// decodeJSONKey reads a key that is either an integer column index or a string.
+func decodeJSONKey(raw json.RawMessage) (any, error) {
+	if len(raw) == 0 {
+		return nil, errors.New(`filterexpr: filter node needs "key"`)
+	}
+
+	var index int
+	if err := json.Unmarshal(raw, &index); err == nil {
+		return index, nil
+	}
+	var key string
+	if err := json.Unmarshal(raw, &key); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("filterexpr: key %s must be an integer or a string", raw)
+}
+
+// decodeJSONValue reads a value that is a string, a number or a boolean, as the text Compile expects.
+func decodeJSONValue(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 {
+		return "", nil
+	}
+
+	var value string
+	if err := json.Unmarshal(raw, &value); err == nil {
+		return value, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var scalar any
+	if err := decoder.Decode(&scalar); err == nil {
+		switch v := scalar.(type) {
+		case json.Number:
+			return v.String(), nil
+		case bool:
+			return strconv.FormatBool(v), nil
+		}
+	}
+	return "", fmt.Errorf("filterexpr: value %s must be a string, a number or a boolean", raw)
+}
+
+// decodeJSONList reads an array of values, see decodeJSONValue.
+func decodeJSONList(raw json.RawMessage) ([]string, error) {
+	var elements []json.RawMessage
+	if err := json.Unmarshal(raw, &elements); err != nil {
+		return nil, err
+	}
+
+	values := make([]string, len(elements))
+	for i, element := range elements {
+		value, err := decodeJSONValue(element)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// MarshalTree encodes a compiled tree as JSON, see Decompile for the requirements on t.
+func MarshalTree(t *filter.FTree, opts Options) ([]byte, error) {
+	expr, err := Decompile(t, opts)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(expr)
+}
+
+// LoadJSON decodes an Expr from JSON and compiles it into a tree whose filter sets read from r.
+func LoadJSON(data []byte, r reader.StreamReader, opts Options) (*filter.FTree, error) {
+	var expr Expr
+	if err := json.Unmarshal(data, &expr); err != nil {
+		return nil, err
+	}
+	return Compile(&expr, r, opts)
+}
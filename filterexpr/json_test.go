@@ -0,0 +1,241 @@
+package filterexpr
+
+import (
+	"encoding/json"
+	"fejsal/filter"
+	"fejsal/reader"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpr_MarshalJSON(t *testing.T) {
+	expr, err := Parse(`(string,1,contain,banana) and not ((number,0,less_than,3) or (string,"007",in,[a,""]))`)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	got, err := json.Marshal(expr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `{"type":"and",` +
+		`"left":{"type":"filter","valueType":"string","key":1,"operator":"contain","value":"banana"},` +
+		`"right":{"type":"not","operand":{"type":"or",` +
+		`"left":{"type":"filter","valueType":"number","key":0,"operator":"less_than","value":"3"},` +
+		`"right":{"type":"filter","valueType":"string","key":"007","operator":"in","value":["a",""]}}}}`
+	if string(got) != want {
+		t.Errorf("marshal result mismatch\nGot: %s\nWant: %s", got, want)
+	}
+
+	var decoded Expr
+	if err := json.Unmarshal(got, &decoded); err != nil {
+		t.Fatalf("unexpected unmarshal error: %v", err)
+	}
+	if !reflect.DeepEqual(&decoded, expr) {
+		t.Errorf("round trip mismatch\nGot: %#v\nWant: %#v", &decoded, expr)
+	}
+}
+
+func TestExpr_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  *Expr
+	}{
+		{
+			name:  "Number value",
+			input: `{"type":"filter","valueType":"number","key":0,"operator":"equal","value":1.50}`,
+			want:  leaf("number", 0, "equal", "1.50"),
+		},
+		{
+			name:  "Boolean value",
+			input: `{"type":"filter","valueType":"string","key":"active","operator":"equal","value":true}`,
+			want:  leaf("string", "active", "equal", "true"),
+		},
+		{
+			name:  "Layout",
+			input: `{"type":"filter","valueType":"datetime","key":4,"operator":"less_than","value":"2025-03-20","layout":"2006-01-02"}`,
+			want: &Expr{Type: NodeFilter, Filter: RawFilter{
+				ValueType: "datetime", Index: 4, Operator: "less_than", Value: "2025-03-20", Layout: "2006-01-02",
+			}},
+		},
+		{
+			name:  "List value",
+			input: `{"type":"filter","valueType":"number","key":0,"operator":"in","value":[1, "2", 3.5]}`,
+			want: &Expr{Type: NodeFilter, Filter: RawFilter{
+				ValueType: "number", Index: 0, Operator: "in", Values: []string{"1", "2", "3.5"},
+			}},
+		},
+		{
+			name:  "Empty list value",
+			input: `{"type":"filter","valueType":"string","key":1,"operator":"in","value":[]}`,
+			want:  &Expr{Type: NodeFilter, Filter: RawFilter{ValueType: "string", Index: 1, Operator: "in", Values: []string{}}},
+		},
+		{
+			name:  "Condition names are accepted for node types",
+			input: `{"type":"OR","left":{"type":"filter","valueType":"string","key":1,"operator":"contain","value":"a"},"right":{"type":"filter","valueType":"string","key":1,"operator":"contain","value":"b"}}`,
+			want:  op(OpOr, leaf("string", 1, "contain", "a"), leaf("string", 1, "contain", "b")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Expr
+			if err := json.Unmarshal([]byte(tt.input), &got); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(&got, tt.want) {
+				t.Errorf("unmarshal result mismatch\nGot: %#v\nWant: %#v", &got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpr_UnmarshalJSON_Error(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "Unknown node type", input: `{"type":"xor"}`},
+		{name: "Missing operand", input: `{"type":"and","left":{"type":"filter","valueType":"string","key":1,"operator":"contain","value":"a"}}`},
+		{name: "Missing not operand", input: `{"type":"not"}`},
+		{name: "Missing key", input: `{"type":"filter","valueType":"string","operator":"contain","value":"a"}`},
+		{name: "Missing operator", input: `{"type":"filter","valueType":"string","key":1,"value":"a"}`},
+		{name: "Fractional key", input: `{"type":"filter","valueType":"string","key":1.5,"operator":"contain","value":"a"}`},
+		{name: "Object value", input: `{"type":"filter","valueType":"string","key":1,"operator":"contain","value":{}}`},
+		{name: "Object in list value", input: `{"type":"filter","valueType":"string","key":1,"operator":"in","value":["a",{}]}`},
+		{name: "Nested error", input: `{"type":"not","operand":{"type":"filter"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Expr
+			if err := json.Unmarshal([]byte(tt.input), &got); err == nil {
+				t.Errorf("expected error for %s", tt.input)
+			}
+		})
+	}
+}
+
+func TestLoadJSON(t *testing.T) {
+	input := `{"type":"and",
+		"left":{"type":"filter","valueType":"datetime","key":4,"operator":"greater_than","value":"2025-03-20","layout":"2006-01-02"},
+		"right":{"type":"not","operand":{"type":"filter","valueType":"string","key":3,"operator":"contain","value":"smoothie"}}}`
+
+	csvReader := reader.NewCSVReader()
+	tree, err := LoadJSON([]byte(input), csvReader, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the layout only covers the date, so every timestamp of the sample fails to parse
+	csvReader.InputStream(strings.NewReader(sampleCSV))
+	for csvReader.LoadNextLine() {
+		if tree.Evaluate() {
+			t.Errorf("expected no match")
+		}
+	}
+}
+
+func TestLoadJSON_OptimizeLayouts(t *testing.T) {
+	// both filters read column 0, but parse it with different layouts, so they must not share a getter
+	input := `{"type":"and",
+		"left":{"type":"filter","valueType":"time","key":0,"operator":">=","value":"2025-03-20","layout":"2006-01-02"},
+		"right":{"type":"filter","valueType":"time","key":0,"operator":"<","value":"2025/03/21","layout":"2006/01/02"}}`
+
+	for _, optimize := range []bool{false, true} {
+		csvReader := reader.NewCSVReader()
+		tree, err := LoadJSON([]byte(input), csvReader, Options{Optimize: optimize})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		csvReader.InputStream(strings.NewReader("2025-03-20\n"))
+		for csvReader.LoadNextLine() {
+			if tree.Evaluate() {
+				t.Errorf("expected no match with Optimize %v", optimize)
+			}
+		}
+	}
+}
+
+func TestMarshalTree_Layout(t *testing.T) {
+	input := `{"type":"filter","valueType":"datetime","key":0,"operator":">","value":"2025-03-20","layout":"2006-01-02"}`
+
+	// matched reports whether the tree loaded from data matches the line 2025-03-21
+	matched := func(data []byte) (bool, *filter.FTree) {
+		t.Helper()
+		csvReader := reader.NewCSVReader()
+		tree, err := LoadJSON(data, csvReader, Options{})
+		if err != nil {
+			t.Fatalf("unexpected load error: %v", err)
+		}
+		csvReader.InputStream(strings.NewReader("2025-03-21\n"))
+		if !csvReader.LoadNextLine() {
+			t.Fatalf("expected a line")
+		}
+		return tree.Evaluate(), tree
+	}
+
+	ok, tree := matched([]byte(input))
+	if !ok {
+		t.Fatalf("expected the loaded tree to match")
+	}
+
+	data, err := MarshalTree(tree, Options{})
+	if err != nil {
+		t.Fatalf("unexpected marshal error: %v", err)
+	}
+	if want := `"value":"2025-03-20","layout":"2006-01-02"`; !strings.Contains(string(data), want) {
+		t.Errorf("expected %s to contain %s", data, want)
+	}
+	if ok, _ := matched(data); !ok {
+		t.Errorf("expected the reloaded tree %s to match", data)
+	}
+}
+
+func TestMarshalTree(t *testing.T) {
+	input := "(datetime,4,greater_than,2025-03-20 00:00:00) and not (string,3,contain,smoothie)"
+	expr, err := Parse(input)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	tree, err := Compile(expr, reader.NewCSVReader(), Options{})
+	if err != nil {
+		t.Fatalf("unexpected compile error: %v", err)
+	}
+	data, err := MarshalTree(tree, Options{})
+	if err != nil {
+		t.Fatalf("unexpected marshal error: %v", err)
+	}
+
+	csvReader := reader.NewCSVReader()
+	loaded, err := LoadJSON(data, csvReader, Options{})
+	if err != nil {
+		t.Fatalf("unexpected load error: %v", err)
+	}
+
+	var matched []string
+	id := csvReader.StringGetter(0)
+	csvReader.InputStream(strings.NewReader(sampleCSV))
+	for csvReader.LoadNextLine() {
+		if loaded.Evaluate() {
+			v, _ := id()
+			matched = append(matched, v)
+		}
+	}
+	if strings.Join(matched, ",") != "2" {
+		t.Errorf("matched lines mismatch\nGot: %v\nWant: [2]", matched)
+	}
+
+	got, err := FormatTree(loaded, Options{})
+	if err != nil {
+		t.Fatalf("unexpected format error: %v", err)
+	}
+	if got != input {
+		t.Errorf("format result mismatch\nGot: %s\nWant: %s", got, input)
+	}
+}
@@ -0,0 +1,241 @@
+package filterexpr
+
+import (
+	"fejsal/filter"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// operatorSpellings lists every accepted spelling of each operator.
+// The lower-cased name of the filter.Operator is always accepted as well and is what Decompile writes.
+var operatorSpellings = []struct {
+	operator  filter.Operator
+	spellings []string
+}{
+	{filter.OperatorEqual, []string{"==", "=", "eq"}},
+	{filter.OperatorNotEqual, []string{"!=", "<>", "ne"}},
+	{filter.OperatorLessThan, []string{"<"}},
+	{filter.OperatorLessThanOrEqual, []string{"<="}},
+	{filter.OperatorGreaterThan, []string{">"}},
+	{filter.OperatorGreaterThanOrEqual, []string{">="}},
+	{filter.OperatorContain, []string{"~"}},
+	{filter.OperatorNotContain, []string{"!contain"}},
+	{filter.OperatorStartsWith, []string{"prefix"}},
+	{filter.OperatorEndsWith, []string{"suffix"}},
+	{filter.OperatorMatch, []string{"=~"}},
+	{filter.OperatorNotMatch, []string{"!~"}},
+	{filter.OperatorGlob, []string{"wildcard"}},
+	{filter.OperatorLike, nil},
+	{filter.OperatorFuzzy, nil},
+	{filter.OperatorIn, nil},
+	{filter.OperatorNotIn, []string{"!in"}},
+	{filter.OperatorInCIDR, []string{"cidr"}},
+	{filter.OperatorNotInCIDR, []string{"!cidr", "!in_cidr"}},
+	{filter.OperatorBetween, nil},
+	{filter.OperatorCaret, []string{"^"}},
+	{filter.OperatorTilde, []string{"~>"}},
+	{filter.OperatorExists, nil},
+	{filter.OperatorNotExists, []string{"missing", "!exists"}},
+	{filter.OperatorIsEmpty, []string{"empty"}},
+}
+
+// valueTypeSpellings lists every accepted spelling of each value type, next to its lower-cased name.
+var valueTypeSpellings = []struct {
+	valueType filter.ValueType
+	spellings []string
+}{
+	{filter.ValueTypeString, []string{"str"}},
+	{filter.ValueTypeNumber, []string{"int", "int64", "uint64", "float"}},
+	{filter.ValueTypeDatetime, []string{"time"}},
+	{filter.ValueTypeBool, []string{"boolean"}},
+	{filter.ValueTypeDuration, []string{"dur"}},
+	{filter.ValueTypeIP, nil},
+	{filter.ValueTypeSemver, []string{"version"}},
+	{filter.ValueTypeDecimal, []string{"dec"}},
+}
+
+var (
+	operatorNames  = map[string]filter.Operator{}
+	valueTypeNames = map[string]filter.ValueType{}
+)
+
+func init() {
+	for _, entry := range operatorSpellings {
+		operatorNames[strings.ToLower(string(entry.operator))] = entry.operator
+		for _, spelling := range entry.spellings {
+			operatorNames[spelling] = entry.operator
+		}
+	}
+	for _, entry := range valueTypeSpellings {
+		valueTypeNames[strings.ToLower(string(entry.valueType))] = entry.valueType
+		for _, spelling := range entry.spellings {
+			valueTypeNames[spelling] = entry.valueType
+		}
+	}
+}
+
+// LookupOperator resolves any spelling of an operator, such as ">=", "contain" or "LESS_THAN", case-insensitively.
+func LookupOperator(name string) (filter.Operator, error) {
+	if operator, ok := operatorNames[strings.ToLower(name)]; ok {
+		return operator, nil
+	}
+
+	valid := make([]string, 0, len(operatorSpellings))
+	for _, entry := range operatorSpellings {
+		valid = append(valid, describeSpellings(string(entry.operator), entry.spellings))
+	}
+	return "", fmt.Errorf("unknown operator %q, valid operators are %s", name, strings.Join(valid, "; "))
+}
+
+// operatorSpec is an operator together with the modifiers of its spelling.
+type operatorSpec struct {
+	operator  filter.Operator
+	matchMode filter.MatchMode
+	distance  int // maximum edit distance of a fuzzy operator, filter.FuzzyAuto unless given
+	fuzzyMode filter.FuzzyMode
+}
+
+// parseOperator resolves an operator spelling with optional modifiers, written after colons:
+//   - ":i" ignores case, which can also be written as an "i" prefix, as in "icontain" or "ieq",
+//   - ":a" ignores accents,
+//   - ":ia" does both.
+//
+// The fuzzy operator also takes its maximum edit distance as a number, as in "fuzzy:2",
+// "d" to count transpositions as one edit and "w" to match words of the data, as in "fuzzy:1:dw".
+func parseOperator(name string) (operatorSpec, error) {
+	base, modifiers, hasModifiers := strings.Cut(name, ":")
+
+	var spec operatorSpec
+	operator, err := LookupOperator(base)
+	if err != nil {
+		lower := strings.ToLower(base)
+		prefixed, ok := operatorNames[strings.TrimPrefix(lower, "i")]
+		if !ok || !strings.HasPrefix(lower, "i") {
+			return operatorSpec{}, err
+		}
+		operator = prefixed
+		spec.matchMode |= filter.MatchIgnoreCase
+	}
+	spec.operator = operator
+	fuzzy := operator == filter.OperatorFuzzy
+	if fuzzy {
+		spec.distance = filter.FuzzyAuto
+	}
+
+	if !hasModifiers {
+		return spec, nil
+	}
+	for _, modifier := range strings.Split(strings.ToLower(modifiers), ":") {
+		if modifier == "" {
+			return operatorSpec{}, fmt.Errorf("empty modifier in operator %q", name)
+		}
+		if distance, err := strconv.Atoi(modifier); err == nil && fuzzy && distance >= 0 {
+			spec.distance = distance
+			continue
+		}
+		for _, c := range modifier {
+			switch {
+			case c == 'i':
+				spec.matchMode |= filter.MatchIgnoreCase
+			case c == 'a':
+				spec.matchMode |= filter.MatchIgnoreAccents
+			case c == 'd' && fuzzy:
+				spec.fuzzyMode |= filter.FuzzyTranspositions
+			case c == 'w' && fuzzy:
+				spec.fuzzyMode |= filter.FuzzyTokens
+			default:
+				valid := "i (ignore case) and a (ignore accents)"
+				if fuzzy {
+					valid = "a distance, i (ignore case), a (ignore accents), d (transpositions) and w (words)"
+				}
+				return operatorSpec{}, fmt.Errorf("unknown modifier %q in operator %q, valid modifiers are %s", modifier, name, valid)
+			}
+		}
+	}
+	return spec, nil
+}
+
+// isPresenceOperator reports whether operator tests the presence of a value instead of comparing it,
+// so its filters take no value and are written as (type,key,operator).
+func isPresenceOperator(operator filter.Operator) bool {
+	return operator == filter.OperatorExists || operator == filter.OperatorNotExists || operator == filter.OperatorIsEmpty
+}
+
+// isPresenceSpelling reports whether name spells a presence operator, see isPresenceOperator.
+func isPresenceSpelling(name string) bool {
+	spec, err := parseOperator(name)
+	return err == nil && isPresenceOperator(spec.operator)
+}
+
+// formatOperator returns the canonical spelling of a filter's operator with its modifiers, which parseOperator reads back.
+func formatOperator(info filter.FilterInfo) string {
+	name := strings.ToLower(string(info.Operator))
+	if info.Operator == filter.OperatorFuzzy && info.Distance != filter.FuzzyAuto {
+		name += ":" + strconv.Itoa(info.Distance)
+	}
+
+	var modifiers string
+	if info.MatchMode&filter.MatchIgnoreCase != 0 {
+		modifiers += "i"
+	}
+	if info.MatchMode&filter.MatchIgnoreAccents != 0 {
+		modifiers += "a"
+	}
+	if info.FuzzyMode&filter.FuzzyTranspositions != 0 {
+		modifiers += "d"
+	}
+	if info.FuzzyMode&filter.FuzzyTokens != 0 {
+		modifiers += "w"
+	}
+	if modifiers != "" {
+		name += ":" + modifiers
+	}
+	return name
+}
+
+// canonicalOperator returns the canonical spelling of an operator spelling with its modifiers, as Decompile writes it,
+// e.g. "less_than" for "<" and "contain:i" for "icontain", or name itself if it is not a valid spelling.
+func canonicalOperator(name string) string {
+	spec, err := parseOperator(name)
+	if err != nil {
+		return name
+	}
+	return formatOperator(filter.FilterInfo{Operator: spec.operator, MatchMode: spec.matchMode, Distance: spec.distance, FuzzyMode: spec.fuzzyMode})
+}
+
+// canonicalValueType returns the lower-cased name of a value type spelling, e.g. "string" for "STR",
+// or name itself if it is not a valid spelling. The number spellings int64, uint64 and float pick a Go type,
+// so they are kept, lower-cased.
+func canonicalValueType(name string) string {
+	valueType, err := LookupValueType(name)
+	if err != nil {
+		return name
+	}
+	switch lower := strings.ToLower(name); lower {
+	case "int64", "uint64", "float":
+		return lower
+	}
+	return strings.ToLower(string(valueType))
+}
+
+// LookupValueType resolves any spelling of a value type, such as "str", "int" or "DATETIME", case-insensitively.
+func LookupValueType(name string) (filter.ValueType, error) {
+	if valueType, ok := valueTypeNames[strings.ToLower(name)]; ok {
+		return valueType, nil
+	}
+
+	valid := make([]string, 0, len(valueTypeSpellings))
+	for _, entry := range valueTypeSpellings {
+		valid = append(valid, describeSpellings(string(entry.valueType), entry.spellings))
+	}
+	return "", fmt.Errorf("unknown value type %q, valid types are %s", name, strings.Join(valid, "; "))
+}
+
+// describeSpellings lists the spellings of one name for an error message, e.g. "equal (==, =)".
+func describeSpellings(name string, spellings []string) string {
+	if len(spellings) == 0 {
+		return strings.ToLower(name)
+	}
+	return fmt.Sprintf("%s (%s)", strings.ToLower(name), strings.Join(spellings, ", "))
+}
@@ -0,0 +1,142 @@
+package filterexpr
+
+import (
+	"fejsal/filter"
+	"strings"
+	"testing"
+)
+
+func TestLookupOperator(t *testing.T) {
+	tests := map[string]filter.Operator{
+		"==":                    filter.OperatorEqual,
+		"=":                     filter.OperatorEqual,
+		"equal":                 filter.OperatorEqual,
+		"EQUAL":                 filter.OperatorEqual,
+		"!=":                    filter.OperatorNotEqual,
+		"<>":                    filter.OperatorNotEqual,
+		"Not_Equal":             filter.OperatorNotEqual,
+		"<":                     filter.OperatorLessThan,
+		"<=":                    filter.OperatorLessThanOrEqual,
+		">":                     filter.OperatorGreaterThan,
+		">=":                    filter.OperatorGreaterThanOrEqual,
+		"greater_than_or_equal": filter.OperatorGreaterThanOrEqual,
+		"contain":               filter.OperatorContain,
+		"CONTAIN":               filter.OperatorContain,
+		"~":                     filter.OperatorContain,
+		"not_contain":           filter.OperatorNotContain,
+		"!contain":              filter.OperatorNotContain,
+		"starts_with":           filter.OperatorStartsWith,
+		"prefix":                filter.OperatorStartsWith,
+		"ENDS_WITH":             filter.OperatorEndsWith,
+		"suffix":                filter.OperatorEndsWith,
+		"=~":                    filter.OperatorMatch,
+		"match":                 filter.OperatorMatch,
+		"!~":                    filter.OperatorNotMatch,
+		"not_match":             filter.OperatorNotMatch,
+		"glob":                  filter.OperatorGlob,
+		"wildcard":              filter.OperatorGlob,
+		"LIKE":                  filter.OperatorLike,
+		"in":                    filter.OperatorIn,
+		"NOT_IN":                filter.OperatorNotIn,
+		"!in":                   filter.OperatorNotIn,
+	}
+
+	for name, want := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := LookupOperator(name)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != want {
+				t.Errorf("lookup result mismatch\nGot: %s\nWant: %s", got, want)
+			}
+		})
+	}
+}
+
+func TestParseOperator(t *testing.T) {
+	tests := map[string]operatorSpec{
+		"contain":     {operator: filter.OperatorContain},
+		"icontain":    {operator: filter.OperatorContain, matchMode: filter.MatchIgnoreCase},
+		"ieq":         {operator: filter.OperatorEqual, matchMode: filter.MatchIgnoreCase},
+		"ine":         {operator: filter.OperatorNotEqual, matchMode: filter.MatchIgnoreCase},
+		"contain:i":   {operator: filter.OperatorContain, matchMode: filter.MatchIgnoreCase},
+		"~:a":         {operator: filter.OperatorContain, matchMode: filter.MatchIgnoreAccents},
+		"=~:IA":       {operator: filter.OperatorMatch, matchMode: filter.MatchIgnoreCase | filter.MatchIgnoreAccents},
+		"iprefix:a":   {operator: filter.OperatorStartsWith, matchMode: filter.MatchIgnoreCase | filter.MatchIgnoreAccents},
+		"equal:i:a":   {operator: filter.OperatorEqual, matchMode: filter.MatchIgnoreCase | filter.MatchIgnoreAccents},
+		"ends_with:i": {operator: filter.OperatorEndsWith, matchMode: filter.MatchIgnoreCase},
+		"in":          {operator: filter.OperatorIn},
+		"iin":         {operator: filter.OperatorIn, matchMode: filter.MatchIgnoreCase},
+		"fuzzy":       {operator: filter.OperatorFuzzy, distance: filter.FuzzyAuto},
+		"fuzzy:2":     {operator: filter.OperatorFuzzy, distance: 2},
+		"fuzzy:1:dw":  {operator: filter.OperatorFuzzy, distance: 1, fuzzyMode: filter.FuzzyTranspositions | filter.FuzzyTokens},
+		"ifuzzy:0":    {operator: filter.OperatorFuzzy, distance: 0, matchMode: filter.MatchIgnoreCase},
+		"fuzzy:ad":    {operator: filter.OperatorFuzzy, distance: filter.FuzzyAuto, matchMode: filter.MatchIgnoreAccents, fuzzyMode: filter.FuzzyTranspositions},
+	}
+
+	for name, want := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := parseOperator(name)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != want {
+				t.Errorf("parse result mismatch\nGot: %+v\nWant: %+v", got, want)
+			}
+		})
+	}
+
+	for _, name := range []string{"contain:x", "contain:", "contain:2", "equal:d", "fuzzy:-1", "fuzzy:x", "iunknown", "i", "=>:i"} {
+		if _, err := parseOperator(name); err == nil {
+			t.Errorf("expected error for %q", name)
+		}
+	}
+}
+
+func TestLookupValueType(t *testing.T) {
+	tests := map[string]filter.ValueType{
+		"string":   filter.ValueTypeString,
+		"str":      filter.ValueTypeString,
+		"STR":      filter.ValueTypeString,
+		"int":      filter.ValueTypeNumber,
+		"number":   filter.ValueTypeNumber,
+		"Float":    filter.ValueTypeNumber,
+		"time":     filter.ValueTypeDatetime,
+		"datetime": filter.ValueTypeDatetime,
+		"DATETIME": filter.ValueTypeDatetime,
+		"bool":     filter.ValueTypeBool,
+		"Boolean":  filter.ValueTypeBool,
+		"duration": filter.ValueTypeDuration,
+		"dur":      filter.ValueTypeDuration,
+		"IP":       filter.ValueTypeIP,
+		"semver":   filter.ValueTypeSemver,
+		"version":  filter.ValueTypeSemver,
+		"decimal":  filter.ValueTypeDecimal,
+		"dec":      filter.ValueTypeDecimal,
+	}
+
+	for name, want := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := LookupValueType(name)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != want {
+				t.Errorf("lookup result mismatch\nGot: %s\nWant: %s", got, want)
+			}
+		})
+	}
+}
+
+func TestLookup_Error(t *testing.T) {
+	_, err := LookupOperator("=>")
+	if err == nil || !strings.Contains(err.Error(), `"=>"`) || !strings.Contains(err.Error(), "greater_than_or_equal (>=)") {
+		t.Errorf("expected error listing valid operators, got %v", err)
+	}
+
+	_, err = LookupValueType("bytes")
+	if err == nil || !strings.Contains(err.Error(), `"bytes"`) || !strings.Contains(err.Error(), "number (int, int64, uint64, float)") {
+		t.Errorf("expected error listing valid types, got %v", err)
+	}
+}
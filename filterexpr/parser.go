@@ -1,10 +1,21 @@
 package filterexpr
 
+import (
+	"fmt"
+	"strconv"
+)
+
 type NodeType int
 
 const (
 	NodeFilter NodeType = iota
 	NodeOp
+	NodeNot // negates Left
+)
+
+const (
+	OpAnd = "and"
+	OpOr  = "or"
 )
 
 type Expr struct {
@@ -20,4 +31,279 @@ type RawFilter struct {
 	Index     any
 	Operator  string
 	Value     string
+	// Values holds the elements of a list value such as [a,b,c], in which case Value is empty.
+	// It is nil for any other value, so an empty list is a non-nil empty slice.
+	Values []string
+	// Layout overrides Options.TimeLayout for a datetime filter.
+	// The expression syntax has no way to set it, so Format leaves it out.
+	Layout string
+}
+
+// Parse turns a filter expression into an Expr tree.
+//
+// The grammar is:
+//
+//	expr    := andExpr { ("or" | "||") andExpr }
+//	andExpr := primary { ("and" | "&&") primary }
+//	primary := ("not" | "!") primary | "(" expr ")" | filter
+//	filter  := "(" type "," key "," operator ["," (value | list)] ")"
+//	list    := "[" [ value { "," value } ] "]"
+//
+// The value may only be left out for operators that take none, such as exists.
+//
+// NOT binds tighter than AND, AND binds tighter than OR and chains of the same operator are grouped
+// from the left, so "a or not b and c or d" is parsed as ((a or ((not b) and c)) or d).
+func Parse(input string) (*Expr, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{input: input, tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok, ok := p.peek(); ok {
+		return nil, p.errorAt(tok, "and, or or end of input")
+	}
+	return expr, nil
+}
+
+type parser struct {
+	input  string
+	tokens []Token
+	pos    int
+}
+
+// errorAt reports that tok was found where expected was needed.
+func (p *parser) errorAt(tok Token, expected string) *SyntaxError {
+	return &SyntaxError{Input: p.input, Offset: tok.Pos, Expected: expected, Found: describeToken(tok)}
+}
+
+// errorAtEnd reports that the input ended where expected was needed.
+func (p *parser) errorAtEnd(expected string) *SyntaxError {
+	return &SyntaxError{Input: p.input, Offset: len(p.input), Expected: expected, Found: endOfInput}
+}
+
+func (p *parser) peek() (Token, bool) {
+	if p.pos >= len(p.tokens) {
+		return Token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *parser) next() (Token, bool) {
+	tok, ok := p.peek()
+	if ok {
+		p.pos++
+	}
+	return tok, ok
+}
+
+// expect consumes the next token and checks that it is of the given type.
+func (p *parser) expect(tokenType TokenType, what string) (Token, error) {
+	tok, ok := p.next()
+	if !ok {
+		return Token{}, p.errorAtEnd(what)
+	}
+	if tok.Type != tokenType {
+		return Token{}, p.errorAt(tok, what)
+	}
+	return tok, nil
+}
+
+func (p *parser) parseOr() (*Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptOp(OpOr) {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &Expr{Type: NodeOp, Op: OpOr, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (*Expr, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptOp(OpAnd) {
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		left = &Expr{Type: NodeOp, Op: OpAnd, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// acceptOp consumes the next token if it is the given logical operator in any of its spellings.
+func (p *parser) acceptOp(op string) bool {
+	tok, ok := p.peek()
+	if !ok || tok.Type != TokenOp || normalizeOp(tok.Value) != op {
+		return false
+	}
+	p.pos++
+	return true
+}
+
+// parsePrimary parses a negation, a parenthesized sub-expression or a filter tuple.
+// The latter two both start with "(", so the token after it decides which one it is:
+// another "(" or a negation starts a sub-expression, anything else a filter tuple.
+func (p *parser) parsePrimary() (*Expr, error) {
+	if tok, ok := p.peek(); ok && tok.Type == TokenNot {
+		p.pos++
+		operand, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		return &Expr{Type: NodeNot, Left: operand}, nil
+	}
+
+	open, err := p.expect(TokenLParen, `"("`)
+	if err != nil {
+		return nil, err
+	}
+
+	tok, ok := p.peek()
+	if !ok {
+		return nil, p.errorAtEnd(`"(" or filter`)
+	}
+
+	var expr *Expr
+	if tok.Type == TokenLParen || tok.Type == TokenNot {
+		expr, err = p.parseOr()
+	} else {
+		expr, err = p.parseFilter(open)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := p.expect(TokenRParen, `")"`); err != nil {
+		return nil, err
+	}
+	return expr, nil
+}
+
+// parseFilter parses the inside of a (type,key,operator,value) tuple opened by open.
+func (p *parser) parseFilter(open Token) (*Expr, error) {
+	parts := make([]Token, 0, 4)
+	var list []string
+	for {
+		tok, ok := p.next()
+		if !ok {
+			return nil, p.errorAtEnd("filter value")
+		}
+		// only the value of a filter can be a list
+		if tok.Type == TokenLBracket && len(parts) == 3 {
+			var err error
+			if list, err = p.parseList(); err != nil {
+				return nil, err
+			}
+		} else if !isValueToken(tok) {
+			return nil, p.errorAt(tok, "filter value")
+		}
+		parts = append(parts, tok)
+
+		sep, ok := p.peek()
+		if !ok || sep.Type != TokenComma {
+			break
+		}
+		p.pos++
+	}
+
+	if len(parts) == 3 && isPresenceSpelling(parts[2].Value) {
+		return &Expr{
+			Type:   NodeFilter,
+			Filter: RawFilter{ValueType: parts[0].Value, Index: parseIndex(parts[1]), Operator: parts[2].Value},
+		}, nil
+	}
+	if len(parts) != 4 {
+		return nil, &SyntaxError{
+			Input:  p.input,
+			Offset: open.Pos,
+			Msg:    fmt.Sprintf("filter must have 4 parts (type,key,operator,value), found %d", len(parts)),
+		}
+	}
+
+	raw := RawFilter{
+		ValueType: parts[0].Value,
+		Index:     parseIndex(parts[1]),
+		Operator:  parts[2].Value,
+	}
+	if list != nil {
+		raw.Values = list
+	} else {
+		raw.Value = parts[3].Value
+	}
+	return &Expr{Type: NodeFilter, Filter: raw}, nil
+}
+
+// parseList parses the values of a list up to and including the closing "]".
+// It returns a non-nil slice, even for an empty list.
+func (p *parser) parseList() ([]string, error) {
+	values := []string{}
+	if tok, ok := p.peek(); ok && tok.Type == TokenRBracket {
+		p.pos++
+		return values, nil
+	}
+
+	for {
+		tok, ok := p.next()
+		if !ok {
+			return nil, p.errorAtEnd("list value")
+		}
+		if !isValueToken(tok) {
+			return nil, p.errorAt(tok, "list value")
+		}
+		values = append(values, tok.Value)
+
+		sep, ok := p.next()
+		if !ok {
+			return nil, p.errorAtEnd(`"," or "]"`)
+		}
+		switch sep.Type {
+		case TokenComma:
+		case TokenRBracket:
+			return values, nil
+		default:
+			return nil, p.errorAt(sep, `"," or "]"`)
+		}
+	}
+}
+
+// isValueToken reports whether tok can be a value inside a tuple.
+// Keywords are plain values there, so (string,1,equal,and) stays valid.
+func isValueToken(tok Token) bool {
+	return tok.Type == TokenValue || tok.Type == TokenOp || tok.Type == TokenNot
+}
+
+// normalizeOp maps every spelling of a logical operator onto OpAnd or OpOr.
+func normalizeOp(op string) string {
+	switch op {
+	case "and", "&&":
+		return OpAnd
+	case "or", "||":
+		return OpOr
+	}
+	return op
+}
+
+// parseIndex turns an unquoted numeric key into a column index (csv) and keeps anything else
+// as a string key (json), so a quoted "3" stays a string.
+func parseIndex(key Token) any {
+	if key.Quoted {
+		return key.Value
+	}
+	if idx, err := strconv.Atoi(key.Value); err == nil {
+		return idx
+	}
+	return key.Value
 }
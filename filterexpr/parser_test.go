@@ -0,0 +1,233 @@
+package filterexpr
+
+import (
+	"reflect"
+	"testing"
+)
+
+func leaf(valueType string, index any, operator, value string) *Expr {
+	return &Expr{
+		Type:   NodeFilter,
+		Filter: RawFilter{ValueType: valueType, Index: index, Operator: operator, Value: value},
+	}
+}
+
+func op(op string, left, right *Expr) *Expr {
+	return &Expr{Type: NodeOp, Op: op, Left: left, Right: right}
+}
+
+func not(operand *Expr) *Expr {
+	return &Expr{Type: NodeNot, Left: operand}
+}
+
+func TestParse(t *testing.T) {
+	a := leaf("string", 1, "contain", "banana")
+	b := leaf("time", 2, ">", "2025-03-20 00:00:00")
+	c := leaf("int", 3, "==", "1000")
+	d := leaf("string", "email", "equal", "x@y.z")
+
+	tests := []struct {
+		name  string
+		input string
+		want  *Expr
+	}{
+		{
+			name:  "Single filter",
+			input: "(string,1,contain,banana)",
+			want:  a,
+		},
+		{
+			name:  "Redundant parentheses",
+			input: "(((string,1,contain,banana)))",
+			want:  a,
+		},
+		{
+			name:  "Nested parentheses",
+			input: "((string,1,contain,banana)or(time,2,>,2025-03-20 00:00:00))and(int,3,==,1000)",
+			want:  op(OpAnd, op(OpOr, a, b), c),
+		},
+		{
+			name:  "AND binds tighter than OR",
+			input: "(string,1,contain,banana) or (time,2,>,2025-03-20 00:00:00) and (int,3,==,1000)",
+			want:  op(OpOr, a, op(OpAnd, b, c)),
+		},
+		{
+			name:  "Symbolic operators",
+			input: "(string,1,contain,banana) || (time,2,>,2025-03-20 00:00:00) && (int,3,==,1000)",
+			want:  op(OpOr, a, op(OpAnd, b, c)),
+		},
+		{
+			name:  "Chain of more than two operands is grouped from the left",
+			input: "(string,1,contain,banana) or (time,2,>,2025-03-20 00:00:00) or (int,3,==,1000) or (string,email,equal,x@y.z)",
+			want:  op(OpOr, op(OpOr, op(OpOr, a, b), c), d),
+		},
+		{
+			name:  "Mixed chain",
+			input: "(string,1,contain,banana) and (time,2,>,2025-03-20 00:00:00) or (int,3,==,1000) and (string,email,equal,x@y.z)",
+			want:  op(OpOr, op(OpAnd, a, b), op(OpAnd, c, d)),
+		},
+		{
+			name:  "Negated group",
+			input: "not ((string,1,contain,banana) or (time,2,>,2025-03-20 00:00:00))",
+			want:  not(op(OpOr, a, b)),
+		},
+		{
+			name:  "NOT binds tighter than AND",
+			input: "!(string,1,contain,banana) and (time,2,>,2025-03-20 00:00:00)",
+			want:  op(OpAnd, not(a), b),
+		},
+		{
+			name:  "Negation in the middle of a chain",
+			input: "(string,1,contain,banana) or not (time,2,>,2025-03-20 00:00:00) and (int,3,==,1000)",
+			want:  op(OpOr, a, op(OpAnd, not(b), c)),
+		},
+		{
+			name:  "Double negation",
+			input: "not not(string,1,contain,banana)",
+			want:  not(not(a)),
+		},
+		{
+			name:  "Negation inside a group",
+			input: "(not (string,1,contain,banana) and (time,2,>,2025-03-20 00:00:00))",
+			want:  op(OpAnd, not(a), b),
+		},
+		{
+			name:  "Negated filter inside a group",
+			input: "(string,1,contain,banana) and (! (time,2,>,2025-03-20 00:00:00))",
+			want:  op(OpAnd, a, not(b)),
+		},
+		{
+			name:  "Keyword as filter value",
+			input: "(string,1,equal,not)",
+			want:  leaf("string", 1, "equal", "not"),
+		},
+		{
+			name:  "Spaces around tuple parts are trimmed",
+			input: "( string , email , equal , x@y.z )",
+			want:  d,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parse result mismatch\nGot: %#v\nWant: %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_Error(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "Empty input", input: ""},
+		{name: "Too few parts", input: "(string,1,contain)"},
+		{name: "Too many parts", input: "(string,1,contain,banana,apple)"},
+		{name: "Empty part", input: "(string,,contain,banana)"},
+		{name: "Missing closing parenthesis", input: "((string,1,contain,banana)"},
+		{name: "Unbalanced closing parenthesis", input: "(string,1,contain,banana))"},
+		{name: "Dangling operator", input: "(string,1,contain,banana) and"},
+		{name: "Missing operator", input: "(string,1,contain,banana)(int,3,==,1000)"},
+		{name: "Bare value", input: "banana"},
+		{name: "Dangling not", input: "(string,1,contain,banana) and not"},
+		{name: "Postfix not", input: "(string,1,contain,banana) not"},
+		{name: "List as key", input: "(string,[1,2],in,a)"},
+		{name: "Three parts without a presence operator", input: "(string,1,equal)"},
+		{name: "Unterminated list", input: "(string,1,in,[a,b)"},
+		{name: "Empty list element", input: "(string,1,in,[a,,b])"},
+		{name: "Nested list", input: "(string,1,in,[a,[b]])"},
+		{name: "Text after list", input: "(string,1,in,[a,b]c)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.input); err == nil {
+				t.Errorf("expected error for %q", tt.input)
+			}
+		})
+	}
+}
+
+func TestParse_Quoted(t *testing.T) {
+	got, err := Parse(`(string,3,equal,"banana, ripe") or (string,'message',contain,'foo(bar)')`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := op(OpOr, leaf("string", 3, "equal", "banana, ripe"), leaf("string", "message", "contain", "foo(bar)"))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parse result mismatch\nGot: %#v\nWant: %#v", got, want)
+	}
+}
+
+func TestParse_Patterns(t *testing.T) {
+	got, err := Parse(`(string,1,=~,"^req-\d{6}$") or (string,2,glob,file\*.txt) or (string,3,like,50\%)`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := op(OpOr, op(OpOr, leaf("string", 1, "=~", `^req-\d{6}$`), leaf("string", 2, "glob", `file\*.txt`)), leaf("string", 3, "like", `50\%`))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parse result mismatch\nGot: %#v\nWant: %#v", got, want)
+	}
+}
+
+func TestParse_Presence(t *testing.T) {
+	got, err := Parse("(string,1,exists) or (int,count,MISSING) or (string,1,empty,x)")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := op(OpOr, op(OpOr,
+		&Expr{Type: NodeFilter, Filter: RawFilter{ValueType: "string", Index: 1, Operator: "exists"}},
+		&Expr{Type: NodeFilter, Filter: RawFilter{ValueType: "int", Index: "count", Operator: "MISSING"}}),
+		leaf("string", 1, "empty", "x"))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parse result mismatch\nGot: %#v\nWant: %#v", got, want)
+	}
+}
+
+func TestParse_BracketsOutsideList(t *testing.T) {
+	got, err := Parse(`(string,1,=~,a[0-9]+) or (string,1,equal,x]) or (string,1,equal,\[y])`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := op(OpOr, op(OpOr, leaf("string", 1, "=~", "a[0-9]+"), leaf("string", 1, "equal", "x]")), leaf("string", 1, "equal", "[y]"))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parse result mismatch\nGot: %#v\nWant: %#v", got, want)
+	}
+}
+
+func TestParse_List(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "Plain values", input: "(string,user,in,[a,b,c])", want: []string{"a", "b", "c"}},
+		{name: "Spaces are trimmed", input: "(string,user,in,[ a , b ])", want: []string{"a", "b"}},
+		{name: "Quoted values keep delimiters", input: `(string,user,in,["a,b", 'c]', ""])`, want: []string{"a,b", "c]", ""}},
+		{name: "Empty list", input: "(string,user,in,[])", want: []string{}},
+		{name: "Escaped bracket", input: `(string,user,in,[a\],b])`, want: []string{"a]", "b"}},
+		{name: "Opening bracket inside a value", input: `(string,user,in,[a[0\],b])`, want: []string{"a[0]", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Type != NodeFilter || got.Filter.Value != "" || !reflect.DeepEqual(got.Filter.Values, tt.want) {
+				t.Errorf("parse result mismatch\nGot: %#v\nWant: %#v", got.Filter.Values, tt.want)
+			}
+		})
+	}
+}
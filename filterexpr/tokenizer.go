@@ -1,55 +1,126 @@
 package filterexpr
 
-import "strings"
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
 
 type TokenType int
 
 const (
-	TokenLParen TokenType = iota // (
-	TokenRParen                  // )
-	TokenOp                      // and, or, &&, ||
-	TokenComma                   // ,
-	TokenValue                   // string, int, time - 1 (column of csv), email (key of json) - contain, equal - banana
+	TokenLParen   TokenType = iota // (
+	TokenRParen                    // )
+	TokenOp                        // and, or, &&, ||
+	TokenComma                     // ,
+	TokenValue                     // string, int, time - 1 (column of csv), email (key of json) - contain, equal - banana
+	TokenNot                       // not, !
+	TokenLBracket                  // [ at the start of a value
+	TokenRBracket                  // ] closing a list
 )
 
 type Token struct {
-	Type  TokenType
-	Value string
+	Type   TokenType
+	Value  string
+	Pos    int  // byte offset of the token in the input
+	Quoted bool // the value was written in quotes
 }
 
+// tokenize splits input into tokens.
+//
+// Values are taken verbatim up to the next delimiter and trimmed of surrounding spaces.
+// A value that starts with a single or double quote runs until the matching quote, so it may contain
+// delimiters and keep its surrounding spaces. Backslash escapes (see readEscape) are decoded both inside
+// and outside quotes, which allows writing a delimiter such as \, in an unquoted value.
+//
+// A "[" at the start of a value opens a list of comma separated values that the next "]" closes.
+// Anywhere else brackets are part of the value, and a literal one can be written as \[ or \] or quoted.
+//
+// Malformed input is reported as a *SyntaxError.
 func tokenize(input string) ([]Token, error) {
 	var tokens []Token
 	var buf strings.Builder
-
-	isKeyword := func(s string) bool {
-		return s == "and" || s == "or" || s == "&&" || s == "||"
-	}
+	start := -1     // offset of the first non-space byte of the word in buf
+	quoted := false // a quoted value or a list was just emitted, only spaces may follow until the next delimiter
+	inList := false // a "[" has not been closed yet
 
 	flushBuf := func() {
-		if buf.Len() == 0 {
+		word := strings.TrimSpace(buf.String())
+		buf.Reset()
+		pos := start
+		start = -1
+		quoted = false
+		if word == "" {
 			return
 		}
-		word := buf.String()
-		if isKeyword(word) {
-			tokens = append(tokens, Token{Type: TokenOp, Value: word})
+		if keywords, ok := splitKeywords(word, pos); ok {
+			tokens = append(tokens, keywords...)
 		} else {
-			tokens = append(tokens, Token{Type: TokenValue, Value: word})
+			tokens = append(tokens, Token{Type: TokenValue, Value: word, Pos: pos})
 		}
-		buf.Reset()
 	}
 
 	for i := 0; i < len(input); i++ {
 		c := input[i]
+		if quoted && !isDelimiter(c) && !isSpace(c) && !(inList && c == ']') {
+			return nil, &SyntaxError{Input: input, Offset: i, Expected: `",", "(" or ")" after quoted value or list`, Found: quoteRune(input[i:])}
+		}
+		if start < 0 && !isSpace(c) {
+			start = i
+		}
+
 		switch c {
 		case '(':
 			flushBuf()
-			tokens = append(tokens, Token{Type: TokenLParen, Value: "("})
+			tokens = append(tokens, Token{Type: TokenLParen, Value: "(", Pos: i})
 		case ')':
 			flushBuf()
-			tokens = append(tokens, Token{Type: TokenRParen, Value: ")"})
+			tokens = append(tokens, Token{Type: TokenRParen, Value: ")", Pos: i})
 		case ',':
 			flushBuf()
-			tokens = append(tokens, Token{Type: TokenComma, Value: ","})
+			tokens = append(tokens, Token{Type: TokenComma, Value: ",", Pos: i})
+		case '[':
+			if start != i {
+				buf.WriteByte(c)
+				continue
+			}
+			if inList {
+				return nil, &SyntaxError{Input: input, Offset: i, Msg: "lists cannot be nested"}
+			}
+			tokens = append(tokens, Token{Type: TokenLBracket, Value: "[", Pos: i})
+			start = -1
+			inList = true
+		case ']':
+			if !inList {
+				buf.WriteByte(c)
+				continue
+			}
+			flushBuf()
+			tokens = append(tokens, Token{Type: TokenRBracket, Value: "]", Pos: i})
+			quoted = true
+			inList = false
+		case '"', '\'':
+			// a quote inside a word such as it's is taken literally
+			if start != i {
+				buf.WriteByte(c)
+				continue
+			}
+			buf.Reset()
+			value, end, err := readQuoted(input, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, Token{Type: TokenValue, Value: value, Pos: i, Quoted: true})
+			start = -1
+			quoted = true
+			i = end - 1
+		case '\\':
+			decoded, end, err := readEscape(input, i)
+			if err != nil {
+				return nil, err
+			}
+			buf.WriteString(decoded)
+			i = end - 1
 		default:
 			buf.WriteByte(c)
 		}
@@ -58,3 +129,111 @@ func tokenize(input string) ([]Token, error) {
 
 	return tokens, nil
 }
+
+// splitKeywords turns a word made only of space separated keywords, such as "and not" between
+// two parenthesized operands, into keyword tokens. pos is the offset of word in the input.
+// It reports false if any part of the word is not a keyword, in which case the word is a value.
+func splitKeywords(word string, pos int) ([]Token, bool) {
+	var tokens []Token
+	rest := word
+	for rest != "" {
+		end := strings.IndexAny(rest, " \t\n\r")
+		if end < 0 {
+			end = len(rest)
+		}
+		part := rest[:end]
+
+		var tokenType TokenType
+		switch part {
+		case "and", "or", "&&", "||":
+			tokenType = TokenOp
+		case "not", "!":
+			tokenType = TokenNot
+		default:
+			return nil, false
+		}
+		tokens = append(tokens, Token{Type: tokenType, Value: part, Pos: pos + len(word) - len(rest)})
+
+		rest = strings.TrimLeft(rest[end:], " \t\n\r")
+	}
+	return tokens, true
+}
+
+func isDelimiter(c byte) bool {
+	return c == '(' || c == ')' || c == ','
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// quoteRune returns the first character of s quoted for use in a SyntaxError.
+func quoteRune(s string) string {
+	r, _ := utf8.DecodeRuneInString(s)
+	return strconv.QuoteRune(r)
+}
+
+// readQuoted decodes the quoted value that starts at input[pos].
+// It returns the decoded value and the offset just past the closing quote.
+func readQuoted(input string, pos int) (string, int, error) {
+	quote := input[pos]
+	var buf strings.Builder
+
+	for i := pos + 1; i < len(input); i++ {
+		switch input[i] {
+		case quote:
+			return buf.String(), i + 1, nil
+		case '\\':
+			decoded, end, err := readEscape(input, i)
+			if err != nil {
+				return "", 0, err
+			}
+			buf.WriteString(decoded)
+			i = end - 1
+		default:
+			buf.WriteByte(input[i])
+		}
+	}
+
+	return "", 0, &SyntaxError{Input: input, Offset: pos, Msg: "unterminated quoted value"}
+}
+
+// readEscape decodes the backslash escape that starts at input[pos].
+// It returns the decoded text and the offset just past the escape.
+//
+// Supported escapes are \" \' \\ \, \( \) \[ \] \n \r \t and the unicode escapes \uXXXX and \UXXXXXXXX.
+// Any other backslash is kept together with the character after it, so the escapes of regular expressions,
+// globs and LIKE patterns such as \d, \* or \% and paths such as C:\logs reach the filter unchanged.
+func readEscape(input string, pos int) (string, int, error) {
+	if pos+1 >= len(input) {
+		return "", 0, &SyntaxError{Input: input, Offset: pos, Msg: "unterminated escape sequence"}
+	}
+
+	switch c := input[pos+1]; c {
+	case '"', '\'', '\\', ',', '(', ')', '[', ']':
+		return string(c), pos + 2, nil
+	case 'n':
+		return "\n", pos + 2, nil
+	case 'r':
+		return "\r", pos + 2, nil
+	case 't':
+		return "\t", pos + 2, nil
+	case 'u', 'U':
+		digits := 4
+		if c == 'U' {
+			digits = 8
+		}
+		end := pos + 2 + digits
+		if end > len(input) {
+			return "", 0, &SyntaxError{Input: input, Offset: pos, Msg: "invalid unicode escape"}
+		}
+		code, err := strconv.ParseUint(input[pos+2:end], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return "", 0, &SyntaxError{Input: input, Offset: pos, Msg: "invalid unicode escape " + input[pos:end]}
+		}
+		return string(rune(code)), end, nil
+	}
+
+	_, size := utf8.DecodeRuneInString(input[pos+1:])
+	return input[pos : pos+1+size], pos + 1 + size, nil
+}
@@ -9,37 +9,201 @@ func TestTokenize(t *testing.T) {
 	input := "((string,1,contain,banana)or(time,2,>,2025-03-20 00:00:00))and(int,3,==,1000)"
 
 	expected := []Token{
-		{Type: TokenLParen, Value: "("},
-		{Type: TokenLParen, Value: "("},
-		{Type: TokenValue, Value: "string"},
-		{Type: TokenComma, Value: ","},
-		{Type: TokenValue, Value: "1"},
-		{Type: TokenComma, Value: ","},
-		{Type: TokenValue, Value: "contain"},
-		{Type: TokenComma, Value: ","},
-		{Type: TokenValue, Value: "banana"},
-		{Type: TokenRParen, Value: ")"},
-		{Type: TokenOp, Value: "or"},
-		{Type: TokenLParen, Value: "("},
-		{Type: TokenValue, Value: "time"},
-		{Type: TokenComma, Value: ","},
-		{Type: TokenValue, Value: "2"},
-		{Type: TokenComma, Value: ","},
-		{Type: TokenValue, Value: ">"},
-		{Type: TokenComma, Value: ","},
-		{Type: TokenValue, Value: "2025-03-20 00:00:00"},
-		{Type: TokenRParen, Value: ")"},
-		{Type: TokenRParen, Value: ")"},
-		{Type: TokenOp, Value: "and"},
-		{Type: TokenLParen, Value: "("},
-		{Type: TokenValue, Value: "int"},
-		{Type: TokenComma, Value: ","},
-		{Type: TokenValue, Value: "3"},
-		{Type: TokenComma, Value: ","},
-		{Type: TokenValue, Value: "=="},
-		{Type: TokenComma, Value: ","},
-		{Type: TokenValue, Value: "1000"},
-		{Type: TokenRParen, Value: ")"},
+		{Type: TokenLParen, Value: "(", Pos: 0},
+		{Type: TokenLParen, Value: "(", Pos: 1},
+		{Type: TokenValue, Value: "string", Pos: 2},
+		{Type: TokenComma, Value: ",", Pos: 8},
+		{Type: TokenValue, Value: "1", Pos: 9},
+		{Type: TokenComma, Value: ",", Pos: 10},
+		{Type: TokenValue, Value: "contain", Pos: 11},
+		{Type: TokenComma, Value: ",", Pos: 18},
+		{Type: TokenValue, Value: "banana", Pos: 19},
+		{Type: TokenRParen, Value: ")", Pos: 25},
+		{Type: TokenOp, Value: "or", Pos: 26},
+		{Type: TokenLParen, Value: "(", Pos: 28},
+		{Type: TokenValue, Value: "time", Pos: 29},
+		{Type: TokenComma, Value: ",", Pos: 33},
+		{Type: TokenValue, Value: "2", Pos: 34},
+		{Type: TokenComma, Value: ",", Pos: 35},
+		{Type: TokenValue, Value: ">", Pos: 36},
+		{Type: TokenComma, Value: ",", Pos: 37},
+		{Type: TokenValue, Value: "2025-03-20 00:00:00", Pos: 38},
+		{Type: TokenRParen, Value: ")", Pos: 57},
+		{Type: TokenRParen, Value: ")", Pos: 58},
+		{Type: TokenOp, Value: "and", Pos: 59},
+		{Type: TokenLParen, Value: "(", Pos: 62},
+		{Type: TokenValue, Value: "int", Pos: 63},
+		{Type: TokenComma, Value: ",", Pos: 66},
+		{Type: TokenValue, Value: "3", Pos: 67},
+		{Type: TokenComma, Value: ",", Pos: 68},
+		{Type: TokenValue, Value: "==", Pos: 69},
+		{Type: TokenComma, Value: ",", Pos: 71},
+		{Type: TokenValue, Value: "1000", Pos: 72},
+		{Type: TokenRParen, Value: ")", Pos: 76},
+	}
+
+	tokens, err := tokenize(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(tokens, expected) {
+		t.Errorf("tokenize result mismatch\nGot: %#v\nWant: %#v", tokens, expected)
+	}
+}
+
+func TestTokenize_Quoted(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+		pos   int
+	}{
+		{name: "Double quotes keep delimiters", input: `"banana, ripe"`, want: "banana, ripe"},
+		{name: "Single quotes keep parentheses", input: `'foo(bar)'`, want: "foo(bar)"},
+		{name: "Quotes keep surrounding spaces", input: `  " padded "  `, want: " padded ", pos: 2},
+		{name: "Quoted keyword stays a value", input: `"and"`, want: "and"},
+		{name: "Escaped double quote", input: `"say \"hi\""`, want: `say "hi"`},
+		{name: "Other quote needs no escape", input: `"it's"`, want: "it's"},
+		{name: "Escaped backslash", input: `'C:\\logs'`, want: `C:\logs`},
+		{name: "Control escapes", input: `"a\nb\tc"`, want: "a\nb\tc"},
+		{name: "Unicode escape", input: `"caf\u00e9"`, want: "café"},
+		{name: "Long unicode escape", input: `"\U0001F34C"`, want: "🍌"},
+		{name: "Empty quoted value", input: `""`, want: ""},
+		{name: "Unknown escape is kept", input: `"\d+-\w"`, want: `\d+-\w`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := tokenize(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expected := []Token{{Type: TokenValue, Value: tt.want, Pos: tt.pos, Quoted: true}}
+			if !reflect.DeepEqual(tokens, expected) {
+				t.Errorf("tokenize result mismatch\nGot: %#v\nWant: %#v", tokens, expected)
+			}
+		})
+	}
+}
+
+func TestTokenize_Unquoted(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "Escaped comma without quotes", input: `banana\, ripe`, want: "banana, ripe"},
+		{name: "Quote inside a word is literal", input: `it's`, want: "it's"},
+		{name: "Unknown escape without quotes is kept", input: `C:\path\file\*.txt`, want: `C:\path\file\*.txt`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := tokenize(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expected := []Token{{Type: TokenValue, Value: tt.want}}
+			if !reflect.DeepEqual(tokens, expected) {
+				t.Errorf("tokenize result mismatch\nGot: %#v\nWant: %#v", tokens, expected)
+			}
+		})
+	}
+}
+
+func TestTokenize_QuotedInFilter(t *testing.T) {
+	input := `(string,'a,b',contain,"x) or (y")`
+
+	expected := []Token{
+		{Type: TokenLParen, Value: "(", Pos: 0},
+		{Type: TokenValue, Value: "string", Pos: 1},
+		{Type: TokenComma, Value: ",", Pos: 7},
+		{Type: TokenValue, Value: "a,b", Pos: 8, Quoted: true},
+		{Type: TokenComma, Value: ",", Pos: 13},
+		{Type: TokenValue, Value: "contain", Pos: 14},
+		{Type: TokenComma, Value: ",", Pos: 21},
+		{Type: TokenValue, Value: "x) or (y", Pos: 22, Quoted: true},
+		{Type: TokenRParen, Value: ")", Pos: 32},
+	}
+
+	tokens, err := tokenize(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(tokens, expected) {
+		t.Errorf("tokenize result mismatch\nGot: %#v\nWant: %#v", tokens, expected)
+	}
+}
+
+func TestTokenize_Error(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "Unterminated double quote", input: `(string,1,equal,"banana)`},
+		{name: "Unterminated single quote", input: `(string,1,equal,'banana)`},
+		{name: "Escaped closing quote", input: `"banana\"`},
+		{name: "Text after quoted value", input: `"banana"split`},
+		{name: "Trailing backslash", input: `banana\`},
+		{name: "Short unicode escape", input: `"\u00e"`},
+		{name: "Invalid unicode escape", input: `"\uZZZZ"`},
+		{name: "Text after list", input: `[a]b`},
+		{name: "Nested list", input: `[a,[b]]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tokenize(tt.input); err == nil {
+				t.Errorf("expected error for %q", tt.input)
+			}
+		})
+	}
+}
+
+func TestTokenize_Not(t *testing.T) {
+	input := "(a) and not !(int,3,!=,1)"
+
+	expected := []Token{
+		{Type: TokenLParen, Value: "(", Pos: 0},
+		{Type: TokenValue, Value: "a", Pos: 1},
+		{Type: TokenRParen, Value: ")", Pos: 2},
+		{Type: TokenOp, Value: "and", Pos: 4},
+		{Type: TokenNot, Value: "not", Pos: 8},
+		{Type: TokenNot, Value: "!", Pos: 12},
+		{Type: TokenLParen, Value: "(", Pos: 13},
+		{Type: TokenValue, Value: "int", Pos: 14},
+		{Type: TokenComma, Value: ",", Pos: 17},
+		{Type: TokenValue, Value: "3", Pos: 18},
+		{Type: TokenComma, Value: ",", Pos: 19},
+		{Type: TokenValue, Value: "!=", Pos: 20},
+		{Type: TokenComma, Value: ",", Pos: 22},
+		{Type: TokenValue, Value: "1", Pos: 23},
+		{Type: TokenRParen, Value: ")", Pos: 24},
+	}
+
+	tokens, err := tokenize(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(tokens, expected) {
+		t.Errorf("tokenize result mismatch\nGot: %#v\nWant: %#v", tokens, expected)
+	}
+}
+
+func TestTokenize_List(t *testing.T) {
+	input := `[a, "b]" ,c[1\]]`
+
+	expected := []Token{
+		{Type: TokenLBracket, Value: "[", Pos: 0},
+		{Type: TokenValue, Value: "a", Pos: 1},
+		{Type: TokenComma, Value: ",", Pos: 2},
+		{Type: TokenValue, Value: "b]", Pos: 4, Quoted: true},
+		{Type: TokenComma, Value: ",", Pos: 9},
+		{Type: TokenValue, Value: "c[1]", Pos: 10},
+		{Type: TokenRBracket, Value: "]", Pos: 15},
 	}
 
 	tokens, err := tokenize(input)
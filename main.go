@@ -2,7 +2,7 @@ package main
 
 import (
 	"bufio"
-	"fejsal/filter"
+	"fejsal/filterexpr"
 	"fejsal/reader"
 	"fmt"
 	"strings"
@@ -21,12 +21,11 @@ func main() {
 2,dog,eat,banana
 3,I,drink,banana smoothie
 `
-	strFilter1, _ := filter.NewFilter(filter.OperatorContain, filter.ValueTypeString, "banana")
-	strFilter2, _ := filter.NewFilter(filter.OperatorNotEqual, filter.ValueTypeString, "banana smoothie")
-
-	intFilter, _ := filter.NewFilter(filter.OperatorLessThan, filter.ValueTypeNumber, 3)
-
-	strFilter3, _ := filter.NewFilter(filter.OperatorContain, filter.ValueTypeString, "o")
+	expr, err := filterexpr.Parse("((string,2,contain,banana) or (string,2,!=,banana smoothie) or (string,1,contain,o)) and (int,0,<,3)")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 
 	numWorkers := 3
 
@@ -37,39 +36,10 @@ func main() {
 		channels[i] = make(chan string, 1000) // buffered channel
 		csvReader := reader.NewCSVReader()
 
-		ft := filter.FTree{
-			Left: &filter.FTree{
-				Left: &filter.FTree{
-					FilterSet: filter.FSet[string]{
-						Filters: []filter.Filter[string]{
-							strFilter1,
-							strFilter2,
-						},
-						Condition:  filter.ConditionOr,
-						DataGetter: csvReader.StringGetter(2),
-					},
-				},
-				Right: &filter.FTree{
-					FilterSet: filter.FSet[string]{
-						Filters: []filter.Filter[string]{
-							strFilter3,
-						},
-						Condition:  filter.ConditionAnd,
-						DataGetter: csvReader.StringGetter(1),
-					},
-				},
-				Condition: filter.ConditionOr,
-			},
-			Right: &filter.FTree{
-				FilterSet: filter.FSet[int]{
-					Filters: []filter.Filter[int]{
-						intFilter,
-					},
-					Condition:  filter.ConditionAnd,
-					DataGetter: csvReader.IntGetter(0),
-				},
-			},
-			Condition: filter.ConditionAnd,
+		ft, err := filterexpr.Compile(expr, csvReader, filterexpr.Options{Optimize: true})
+		if err != nil {
+			fmt.Println(err)
+			return
 		}
 
 		wg.Add(1)
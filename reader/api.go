@@ -1,17 +1,36 @@
 package reader
 
 import (
+	"fejsal/filter"
 	"io"
+	"net/netip"
 	"time"
 )
 
 // StreamReader defined methods to handle reading data line-by-line from a stream source
 type StreamReader interface {
 	LoadNextLine() bool
+	// Err returns the error that made LoadNextLine report false, or nil if the input ended.
+	Err() error
 	InputStream(input io.Reader)
 	StringGetter(key any) func() (string, bool)
 	IntGetter(key any) func() (int, bool)
+	Int64Getter(key any) func() (int64, bool)
+	Uint64Getter(key any) func() (uint64, bool)
+	FloatGetter(key any) func() (float64, bool)
 	TimeGetter(key any, layout string) func() (time.Time, bool)
+	// DurationGetter reads a field with ParseDuration, so bare numbers count in unit.
+	DurationGetter(key any, unit time.Duration) func() (time.Duration, bool)
+	// IPGetter reads an IPv4 or IPv6 address such as "10.0.0.1" or "fd00::1".
+	IPGetter(key any) func() (netip.Addr, bool)
+	// SemverGetter reads a semantic version such as "v2.10.3-beta.1" with filter.ParseSemver.
+	SemverGetter(key any) func() (filter.Semver, bool)
+	// DecimalGetter reads an exact decimal such as "1234.50" with filter.ParseDecimal, without going through float64.
+	DecimalGetter(key any) func() (filter.Decimal, bool)
+	// BoolGetter reads a field spelled as one of the reader's BoolSpellings, see DefaultBoolSpellings.
+	BoolGetter(key any) func() (bool, bool)
+	// ValidateKey reports whether key can address a field of this reader, e.g. a column name of a csv header.
+	ValidateKey(key any) error
 
 	read(key any) (string, bool)
 }
@@ -0,0 +1,31 @@
+package reader
+
+import "strings"
+
+// BoolSpellings lists the texts a BoolGetter reads as true and as false, compared case-insensitively.
+// A text that is neither is reported as not found, like a number that does not parse.
+type BoolSpellings struct {
+	True  []string
+	False []string
+}
+
+// DefaultBoolSpellings is used by readers until SetBoolSpellings is called.
+var DefaultBoolSpellings = BoolSpellings{
+	True:  []string{"true", "yes", "y", "on", "t", "1"},
+	False: []string{"false", "no", "n", "off", "f", "0"},
+}
+
+// Parse reads text as a bool, reporting false if text is none of the spellings.
+func (s BoolSpellings) Parse(text string) (bool, bool) {
+	for _, spelling := range s.True {
+		if strings.EqualFold(text, spelling) {
+			return true, true
+		}
+	}
+	for _, spelling := range s.False {
+		if strings.EqualFold(text, spelling) {
+			return false, true
+		}
+	}
+	return false, false
+}
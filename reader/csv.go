@@ -3,19 +3,34 @@ package reader
 import (
 	"bufio"
 	"bytes"
+	"fejsal/filter"
+	"fmt"
 	"io"
+	"net/netip"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
 
 // CSVReader 는 입력된 csv 형태 문자열을 Token 의 형태로 조회할 수 있도록 한다.
 // 문자열 입력 --> 버퍼에 보관 --> Token 형태로 조회
+//
+// Columns are addressed by their int index, or by their name (string key) once the reader has a header,
+// either set with SetHeader or read from the first line by a reader from NewCSVReaderWithHeader.
 type CSVReader struct {
 	lineScanner *bufio.Scanner
 	inputBuffer *bytes.Buffer
 	readBuffer  *bytes.Buffer
 	mu          sync.Mutex
+
+	hasHeader   bool
+	columns     []string
+	columnIndex map[string]int
+	pending     []string // names accepted by ValidateKey before the header was known
+	err         error
+
+	boolSpellings BoolSpellings
 }
 
 func NewCSVReader() *CSVReader {
@@ -24,7 +39,90 @@ func NewCSVReader() *CSVReader {
 		inputBuffer: ib,
 		lineScanner: bufio.NewScanner(ib),
 		readBuffer:  bytes.NewBuffer(make([]byte, 0, 1024)),
+
+		boolSpellings: DefaultBoolSpellings,
+	}
+}
+
+// NewCSVReaderWithHeader returns a CSVReader that takes the first loaded line as the header
+// naming the columns, instead of returning it from LoadNextLine.
+func NewCSVReaderWithHeader() *CSVReader {
+	c := NewCSVReader()
+	c.hasHeader = true
+	return c
+}
+
+// SetHeader names the columns of the reader.
+// A reader from NewCSVReaderWithHeader then no longer expects a header line.
+func (c *CSVReader) SetHeader(columns []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.setHeader(columns)
+}
+
+func (c *CSVReader) setHeader(columns []string) {
+	c.hasHeader = true
+	c.columns = columns
+	c.columnIndex = make(map[string]int, len(columns))
+	for i, name := range columns {
+		// the first of duplicate names wins
+		if _, ok := c.columnIndex[name]; !ok {
+			c.columnIndex[name] = i
+		}
 	}
+
+	for _, name := range c.pending {
+		if _, ok := c.columnIndex[name]; !ok && c.err == nil {
+			c.err = fmt.Errorf("%w %q", ErrUnknownColumn, name)
+		}
+	}
+	c.pending = nil
+}
+
+// SetBoolSpellings replaces the texts BoolGetter reads as true and as false, e.g. "Y" and "N" only.
+func (c *CSVReader) SetBoolSpellings(spellings BoolSpellings) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.boolSpellings = spellings
+}
+
+// Columns returns the column names of the header, or nil if the header is not known (yet).
+func (c *CSVReader) Columns() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.columns
+}
+
+// ValidateKey accepts non-negative column indexes and, if the reader has a header, column names.
+// A name cannot be checked before a header line is loaded, so it is accepted until then
+// and checked once the header is known: an unknown one makes LoadNextLine fail, see Err.
+func (c *CSVReader) ValidateKey(key any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch k := key.(type) {
+	case int:
+		if k < 0 {
+			return fmt.Errorf("%w: negative column index %d", ErrInvalidKey, k)
+		}
+		return nil
+	case string:
+		if !c.hasHeader {
+			return fmt.Errorf("%w: %q", ErrNoHeader, k)
+		}
+		if c.columnIndex == nil {
+			c.pending = append(c.pending, k)
+			return nil
+		}
+		if _, ok := c.columnIndex[k]; !ok {
+			return fmt.Errorf("%w %q", ErrUnknownColumn, k)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: csv columns are addressed by int or string, got %T", ErrInvalidKey, key)
 }
 
 func (c *CSVReader) InputStream(input io.Reader) {
@@ -37,11 +135,22 @@ func (c *CSVReader) InputStream(input io.Reader) {
 	}
 }
 
+// LoadNextLine loads the next line of the input and reports whether there was one.
+// It also reports false, without loading a line, once Err returns an error.
 func (c *CSVReader) LoadNextLine() bool {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	c.readBuffer.Reset()
+	if c.hasHeader && c.columns == nil {
+		if !c.lineScanner.Scan() {
+			return false
+		}
+		c.setHeader(splitFields(c.lineScanner.Bytes()))
+	}
+	if c.err != nil {
+		return false
+	}
 	if c.lineScanner.Scan() {
 		c.readBuffer.Write(c.lineScanner.Bytes())
 		return true
@@ -49,11 +158,20 @@ func (c *CSVReader) LoadNextLine() bool {
 	return false
 }
 
+// Err returns the error that stopped LoadNextLine, such as a column name accepted by ValidateKey
+// that the header loaded afterwards does not have (ErrUnknownColumn), or nil at the end of the input.
+func (c *CSVReader) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.err
+}
+
 func (c *CSVReader) read(key any) (string, bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	idx, ok := key.(int)
+	idx, ok := c.columnOf(key)
 	if !ok {
 		return "", false
 	}
@@ -78,6 +196,27 @@ func (c *CSVReader) read(key any) (string, bool) {
 	return "", false
 }
 
+// columnOf resolves a column index or name to the index of the column.
+func (c *CSVReader) columnOf(key any) (int, bool) {
+	switch k := key.(type) {
+	case int:
+		return k, true
+	case string:
+		idx, ok := c.columnIndex[k]
+		return idx, ok
+	}
+	return 0, false
+}
+
+// splitFields splits a line into its trimmed fields.
+func splitFields(line []byte) []string {
+	fields := strings.Split(string(line), ",")
+	for i, field := range fields {
+		fields[i] = strings.TrimSpace(field)
+	}
+	return fields
+}
+
 func (c *CSVReader) StringGetter(idx any) func() (string, bool) {
 	return func() (string, bool) {
 		return c.read(idx)
@@ -95,6 +234,39 @@ func (c *CSVReader) IntGetter(idx any) func() (int, bool) {
 	}
 }
 
+func (c *CSVReader) Int64Getter(idx any) func() (int64, bool) {
+	return func() (int64, bool) {
+		str, ok := c.read(idx)
+		if !ok {
+			return 0, false
+		}
+		val, err := strconv.ParseInt(str, 10, 64)
+		return val, err == nil
+	}
+}
+
+func (c *CSVReader) Uint64Getter(idx any) func() (uint64, bool) {
+	return func() (uint64, bool) {
+		str, ok := c.read(idx)
+		if !ok {
+			return 0, false
+		}
+		val, err := strconv.ParseUint(str, 10, 64)
+		return val, err == nil
+	}
+}
+
+func (c *CSVReader) FloatGetter(idx any) func() (float64, bool) {
+	return func() (float64, bool) {
+		str, ok := c.read(idx)
+		if !ok {
+			return 0, false
+		}
+		val, err := strconv.ParseFloat(str, 64)
+		return val, err == nil
+	}
+}
+
 func (c *CSVReader) TimeGetter(idx any, layout string) func() (time.Time, bool) {
 	return func() (time.Time, bool) {
 		str, ok := c.read(idx)
@@ -105,3 +277,60 @@ func (c *CSVReader) TimeGetter(idx any, layout string) func() (time.Time, bool)
 		return t, err == nil
 	}
 }
+
+func (c *CSVReader) DurationGetter(idx any, unit time.Duration) func() (time.Duration, bool) {
+	return func() (time.Duration, bool) {
+		str, ok := c.read(idx)
+		if !ok {
+			return 0, false
+		}
+		d, err := ParseDuration(str, unit)
+		return d, err == nil
+	}
+}
+
+func (c *CSVReader) IPGetter(idx any) func() (netip.Addr, bool) {
+	return func() (netip.Addr, bool) {
+		str, ok := c.read(idx)
+		if !ok {
+			return netip.Addr{}, false
+		}
+		addr, err := netip.ParseAddr(str)
+		return addr, err == nil
+	}
+}
+
+func (c *CSVReader) SemverGetter(idx any) func() (filter.Semver, bool) {
+	return func() (filter.Semver, bool) {
+		str, ok := c.read(idx)
+		if !ok {
+			return filter.Semver{}, false
+		}
+		v, err := filter.ParseSemver(str)
+		return v, err == nil
+	}
+}
+
+func (c *CSVReader) DecimalGetter(idx any) func() (filter.Decimal, bool) {
+	return func() (filter.Decimal, bool) {
+		str, ok := c.read(idx)
+		if !ok {
+			return filter.Decimal{}, false
+		}
+		d, err := filter.ParseDecimal(str)
+		return d, err == nil
+	}
+}
+
+func (c *CSVReader) BoolGetter(idx any) func() (bool, bool) {
+	return func() (bool, bool) {
+		str, ok := c.read(idx)
+		if !ok {
+			return false, false
+		}
+		c.mu.Lock()
+		spellings := c.boolSpellings
+		c.mu.Unlock()
+		return spellings.Parse(str)
+	}
+}
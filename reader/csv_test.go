@@ -0,0 +1,238 @@
+package reader
+
+import (
+	"errors"
+	"fejsal/filter"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSVReader_Header(t *testing.T) {
+	c := NewCSVReaderWithHeader()
+	assert.Nil(t, c.Columns())
+
+	c.InputStream(strings.NewReader("id, name ,city\n1,monkey,Belgrade\n2,dog,Novi Sad\n"))
+	name := c.StringGetter("name")
+	id := c.IntGetter("id")
+	missing := c.StringGetter("country")
+
+	var names []string
+	var ids []int
+	for c.LoadNextLine() {
+		n, ok := name()
+		assert.True(t, ok)
+		names = append(names, n)
+
+		i, ok := id()
+		assert.True(t, ok)
+		ids = append(ids, i)
+
+		_, ok = missing()
+		assert.False(t, ok)
+	}
+
+	assert.Equal(t, []string{"id", "name", "city"}, c.Columns())
+	assert.Equal(t, []string{"monkey", "dog"}, names)
+	assert.Equal(t, []int{1, 2}, ids)
+}
+
+func TestCSVReader_SetHeader(t *testing.T) {
+	c := NewCSVReader()
+	c.SetHeader([]string{"id", "name"})
+	c.InputStream(strings.NewReader("1,monkey\n"))
+
+	assert.True(t, c.LoadNextLine())
+	name, ok := c.StringGetter("name")()
+	assert.True(t, ok)
+	assert.Equal(t, "monkey", name)
+
+	byIndex, ok := c.StringGetter(1)()
+	assert.True(t, ok)
+	assert.Equal(t, "monkey", byIndex)
+}
+
+func TestCSVReader_ValidateKey(t *testing.T) {
+	plain := NewCSVReader()
+	assert.NoError(t, plain.ValidateKey(2))
+	assert.True(t, errors.Is(plain.ValidateKey(-1), ErrInvalidKey))
+	assert.True(t, errors.Is(plain.ValidateKey("name"), ErrNoHeader))
+	assert.True(t, errors.Is(plain.ValidateKey(1.5), ErrInvalidKey))
+
+	// the header is not loaded yet, so any name is accepted
+	header := NewCSVReaderWithHeader()
+	assert.NoError(t, header.ValidateKey("name"))
+
+	header.InputStream(strings.NewReader("id,name\n1,monkey\n"))
+	assert.True(t, header.LoadNextLine())
+	assert.NoError(t, header.Err())
+	assert.NoError(t, header.ValidateKey("name"))
+	assert.True(t, errors.Is(header.ValidateKey("city"), ErrUnknownColumn))
+}
+
+func TestCSVReader_ValidateKeyBeforeHeader(t *testing.T) {
+	c := NewCSVReaderWithHeader()
+	assert.NoError(t, c.ValidateKey("name"))
+	assert.NoError(t, c.ValidateKey("nmae"))
+
+	// the header does not have the misspelled name, so no line is loaded
+	c.InputStream(strings.NewReader("id,name\n1,monkey\n"))
+	assert.False(t, c.LoadNextLine())
+	assert.True(t, errors.Is(c.Err(), ErrUnknownColumn))
+	assert.ErrorContains(t, c.Err(), `"nmae"`)
+	assert.False(t, c.LoadNextLine())
+
+	// a header set afterwards is checked the same way
+	set := NewCSVReaderWithHeader()
+	assert.NoError(t, set.ValidateKey("city"))
+	set.SetHeader([]string{"id", "name"})
+	set.InputStream(strings.NewReader("1,monkey\n"))
+	assert.False(t, set.LoadNextLine())
+	assert.True(t, errors.Is(set.Err(), ErrUnknownColumn))
+}
+
+func TestCSVReader_BoolGetter(t *testing.T) {
+	c := NewCSVReader()
+	c.InputStream(strings.NewReader("true,YES,1,off,N,maybe\n"))
+	assert.True(t, c.LoadNextLine())
+
+	wants := []struct {
+		value bool
+		ok    bool
+	}{{true, true}, {true, true}, {true, true}, {false, true}, {false, true}, {false, false}}
+	for i, want := range wants {
+		value, ok := c.BoolGetter(i)()
+		assert.Equal(t, want.ok, ok, "column %d", i)
+		assert.Equal(t, want.value, value, "column %d", i)
+	}
+	_, ok := c.BoolGetter(9)()
+	assert.False(t, ok)
+
+	c.SetBoolSpellings(BoolSpellings{True: []string{"ja"}, False: []string{"nein"}})
+	c.InputStream(strings.NewReader("Ja,nein,true\n"))
+	assert.True(t, c.LoadNextLine())
+
+	value, ok := c.BoolGetter(0)()
+	assert.True(t, ok)
+	assert.True(t, value)
+	value, ok = c.BoolGetter(1)()
+	assert.True(t, ok)
+	assert.False(t, value)
+	_, ok = c.BoolGetter(2)()
+	assert.False(t, ok)
+}
+
+func TestCSVReader_DurationGetter(t *testing.T) {
+	c := NewCSVReader()
+	c.InputStream(strings.NewReader("250ms,1.5s,2m3s,250,0.5,slow\n"))
+	assert.True(t, c.LoadNextLine())
+
+	wants := []struct {
+		value time.Duration
+		ok    bool
+	}{
+		{250 * time.Millisecond, true},
+		{1500 * time.Millisecond, true},
+		{2*time.Minute + 3*time.Second, true},
+		{250 * time.Millisecond, true},
+		{500 * time.Microsecond, true},
+		{0, false},
+	}
+	for i, want := range wants {
+		value, ok := c.DurationGetter(i, time.Millisecond)()
+		assert.Equal(t, want.ok, ok, "column %d", i)
+		assert.Equal(t, want.value, value, "column %d", i)
+	}
+
+	// without a unit only Go durations are read
+	_, ok := c.DurationGetter(3, 0)()
+	assert.False(t, ok)
+	value, ok := c.DurationGetter(0, 0)()
+	assert.True(t, ok)
+	assert.Equal(t, 250*time.Millisecond, value)
+}
+
+func TestCSVReader_IPGetter(t *testing.T) {
+	c := NewCSVReader()
+	c.InputStream(strings.NewReader("10.0.0.1,fd00::1,10.0.0.0/8,localhost\n"))
+	assert.True(t, c.LoadNextLine())
+
+	addr, ok := c.IPGetter(0)()
+	assert.True(t, ok)
+	assert.Equal(t, netip.MustParseAddr("10.0.0.1"), addr)
+
+	addr, ok = c.IPGetter(1)()
+	assert.True(t, ok)
+	assert.Equal(t, netip.MustParseAddr("fd00::1"), addr)
+
+	_, ok = c.IPGetter(2)()
+	assert.False(t, ok)
+	_, ok = c.IPGetter(3)()
+	assert.False(t, ok)
+	_, ok = c.IPGetter(4)()
+	assert.False(t, ok)
+}
+
+func TestCSVReader_SemverGetter(t *testing.T) {
+	c := NewCSVReader()
+	c.InputStream(strings.NewReader("v2.10.3-beta.1,latest\n"))
+	assert.True(t, c.LoadNextLine())
+
+	v, ok := c.SemverGetter(0)()
+	assert.True(t, ok)
+	assert.Equal(t, filter.Semver{Major: 2, Minor: 10, Patch: 3, Prerelease: "beta.1"}, v)
+
+	_, ok = c.SemverGetter(1)()
+	assert.False(t, ok)
+	_, ok = c.SemverGetter(2)()
+	assert.False(t, ok)
+}
+
+func TestCSVReader_DecimalGetter(t *testing.T) {
+	c := NewCSVReader()
+	c.InputStream(strings.NewReader("1234.50,-0.07,12.5 EUR\n"))
+	assert.True(t, c.LoadNextLine())
+
+	d, ok := c.DecimalGetter(0)()
+	assert.True(t, ok)
+	assert.Equal(t, filter.NewDecimal(123450, 2), d)
+
+	d, ok = c.DecimalGetter(1)()
+	assert.True(t, ok)
+	assert.Equal(t, "-0.07", d.String())
+
+	_, ok = c.DecimalGetter(2)()
+	assert.False(t, ok)
+	_, ok = c.DecimalGetter(3)()
+	assert.False(t, ok)
+}
+
+func TestCSVReader_NumberGetters(t *testing.T) {
+	c := NewCSVReader()
+	c.InputStream(strings.NewReader("9007199254740993,-9007199254740993,18446744073709551615,1.5\n"))
+	assert.True(t, c.LoadNextLine())
+
+	i, ok := c.Int64Getter(0)()
+	assert.True(t, ok)
+	assert.Equal(t, int64(9007199254740993), i)
+	i, ok = c.Int64Getter(1)()
+	assert.True(t, ok)
+	assert.Equal(t, int64(-9007199254740993), i)
+	_, ok = c.Int64Getter(2)()
+	assert.False(t, ok)
+
+	u, ok := c.Uint64Getter(2)()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(18446744073709551615), u)
+	_, ok = c.Uint64Getter(1)()
+	assert.False(t, ok)
+
+	f, ok := c.FloatGetter(3)()
+	assert.True(t, ok)
+	assert.Equal(t, 1.5, f)
+	_, ok = c.FloatGetter(4)()
+	assert.False(t, ok)
+}
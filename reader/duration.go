@@ -0,0 +1,22 @@
+package reader
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ParseDuration reads text as a Go duration such as "250ms", "1.5s" or "2m3s", or as a bare number of unit,
+// so "250" is 250ms for a unit of time.Millisecond. A unit of 0 accepts Go durations only.
+func ParseDuration(text string, unit time.Duration) (time.Duration, error) {
+	d, err := time.ParseDuration(text)
+	if err == nil {
+		return d, nil
+	}
+	if unit > 0 {
+		if n, err := strconv.ParseFloat(text, 64); err == nil {
+			return time.Duration(n * float64(unit)), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid duration %q", text)
+}
@@ -0,0 +1,9 @@
+package reader
+
+import "errors"
+
+var (
+	ErrUnknownColumn = errors.New("unknown column")
+	ErrNoHeader      = errors.New("column names need a header")
+	ErrInvalidKey    = errors.New("invalid key")
+)